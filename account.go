@@ -0,0 +1,161 @@
+package dnsdb
+
+// Imports
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+)
+
+// Quota as described at https://docs.dnsdb.info/dnsdb-apiv2/#service-limits-and-quotas
+// Values the API reports as "unlimited" or "n/a" or omits are -1, such times are nil.
+type Quota struct {
+	Limit       int
+	Remaining   int
+	Reset       *Timestamp
+	Expires     *Timestamp
+	ResultsMax  int
+	OffsetMax   int
+	BurstSize   int
+	BurstWindow int // In seconds
+}
+
+// TimeBased reports if the quota is a daily quota that resets, as opposed to a block quota that expires
+func (q Quota) TimeBased() bool {
+	return q.Reset != nil
+}
+
+// Block reports if the quota is a block of queries that expires, as opposed to a daily quota that resets
+func (q Quota) Block() bool {
+	return q.Reset == nil && q.Expires != nil
+}
+
+// quotaInt decodes an integer quota value which may instead be "unlimited" or "n/a"
+func quotaInt(data json.RawMessage) (int, error) {
+	if len(data) == 0 || string(data) == "null" {
+		return -1, nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		if s == "unlimited" || s == "n/a" {
+			return -1, nil
+		}
+		return strconv.Atoi(s)
+	}
+	return strconv.Atoi(string(data))
+}
+
+// quotaTime decodes a unix time quota value which may instead be "n/a"
+func quotaTime(data json.RawMessage) (*Timestamp, error) {
+	i, err := quotaInt(data)
+	if err != nil || i < 0 {
+		return nil, err
+	}
+	return NewTimestamp(int64(i)), nil
+}
+
+// UnmarshalJSON decodes a quota, mapping the special string values
+func (q *Quota) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Limit       json.RawMessage `json:"limit"`
+		Remaining   json.RawMessage `json:"remaining"`
+		Reset       json.RawMessage `json:"reset"`
+		Expires     json.RawMessage `json:"expires"`
+		ResultsMax  json.RawMessage `json:"results_max"`
+		OffsetMax   json.RawMessage `json:"offset_max"`
+		BurstSize   json.RawMessage `json:"burst_size"`
+		BurstWindow json.RawMessage `json:"burst_window"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	var err error
+	for _, f := range []struct {
+		dst *int
+		src json.RawMessage
+	}{
+		{&q.Limit, raw.Limit},
+		{&q.Remaining, raw.Remaining},
+		{&q.ResultsMax, raw.ResultsMax},
+		{&q.OffsetMax, raw.OffsetMax},
+		{&q.BurstSize, raw.BurstSize},
+		{&q.BurstWindow, raw.BurstWindow},
+	} {
+		if *f.dst, err = quotaInt(f.src); err != nil {
+			return err
+		}
+	}
+	if q.Reset, err = quotaTime(raw.Reset); err != nil {
+		return err
+	}
+	if q.Expires, err = quotaTime(raw.Expires); err != nil {
+		return err
+	}
+	return nil
+}
+
+// AccountService communicates with the account related methods of the DNSDB API.
+// These calls do not count against the quota.
+type AccountService service
+
+// RateLimit fetches the current quota of the API key
+func (s *AccountService) RateLimit(ctx context.Context) (*Quota, *Response, error) {
+	path := "lookup/rate_limit"
+	if s.client.APIVersion == APIv2 {
+		path = v2Prefix + "rate_limit"
+	}
+	req, err := s.client.NewRequest(ctx, "GET", path, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header.Add("Accept", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, resp, err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Rate *Quota `json:"rate"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, resp, err
+	}
+	if result.Rate == nil {
+		return nil, resp, errors.New("dnsdb: rate_limit response has no rate")
+	}
+	rate := Rate{Limit: result.Rate.Limit, Remaining: result.Rate.Remaining}
+	if result.Rate.Reset != nil {
+		rate.Reset = *result.Rate.Reset
+	}
+	s.client.setRate(rate)
+	return result.Rate, resp, nil
+}
+
+// Ping verifies connectivity and credentials, it always uses APIv2 regardless of the client
+func (s *AccountService) Ping(ctx context.Context) (*Response, error) {
+	req, err := s.client.NewRequest(ctx, "GET", v2Prefix+"ping", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add("Accept", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return resp, err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Ping string `json:"ping"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return resp, err
+	}
+	if result.Ping != "ok" {
+		return resp, errors.New("dnsdb: unexpected ping response")
+	}
+	return resp, nil
+}
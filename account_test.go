@@ -0,0 +1,103 @@
+package dnsdb
+
+import (
+	"github.com/stretchr/testify/assert"
+
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func Test_AccountService_RateLimit(t *testing.T) {
+	// Setup a client
+	c := NewClient(nil)
+
+	// Verify that an error response fails
+	errorServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "Oh No", 500)
+	}))
+	defer errorServer.Close()
+	u, err := url.Parse(errorServer.URL)
+	assert.Nil(t, err)
+	c.BaseURL = u
+	_, _, err = c.Account.RateLimit(context.Background())
+	assert.NotNil(t, err)
+
+	// Verify that a time based quota is parsed correctly
+	timeServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/lookup/rate_limit", r.URL.Path)
+		io.WriteString(w, `{"rate":{"reset":1433980800,"limit":1000,"remaining":999,"results_max":256,"offset_max":3000000,"burst_size":10,"burst_window":300}}`)
+	}))
+	defer timeServer.Close()
+	u, err = url.Parse(timeServer.URL)
+	assert.Nil(t, err)
+	c.BaseURL = u
+	quota, _, err := c.Account.RateLimit(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, &Quota{
+		Limit:       1000,
+		Remaining:   999,
+		Reset:       NewTimestamp(1433980800),
+		ResultsMax:  256,
+		OffsetMax:   3000000,
+		BurstSize:   10,
+		BurstWindow: 300,
+	}, quota)
+	assert.True(t, quota.TimeBased())
+	assert.False(t, quota.Block())
+
+	// Verify that a block quota is parsed correctly
+	c.APIVersion = APIv2
+	blockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/dnsdb/v2/rate_limit", r.URL.Path)
+		io.WriteString(w, `{"rate":{"reset":"n/a","limit":"unlimited","remaining":"n/a","expires":1435000000,"results_max":1000000,"offset_max":"n/a"}}`)
+	}))
+	defer blockServer.Close()
+	u, err = url.Parse(blockServer.URL)
+	assert.Nil(t, err)
+	c.BaseURL = u
+	quota, _, err = c.Account.RateLimit(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, &Quota{
+		Limit:       -1,
+		Remaining:   -1,
+		Expires:     NewTimestamp(1435000000),
+		ResultsMax:  1000000,
+		OffsetMax:   -1,
+		BurstSize:   -1,
+		BurstWindow: -1,
+	}, quota)
+	assert.False(t, quota.TimeBased())
+	assert.True(t, quota.Block())
+}
+
+func Test_AccountService_Ping(t *testing.T) {
+	// Setup a client
+	c := NewClient(nil)
+
+	// Verify that an unexpected response fails
+	badServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"ping":"nope"}`)
+	}))
+	defer badServer.Close()
+	u, err := url.Parse(badServer.URL)
+	assert.Nil(t, err)
+	c.BaseURL = u
+	_, err = c.Account.Ping(context.Background())
+	assert.NotNil(t, err)
+
+	// Verify that a successful ping succeeds
+	okServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/dnsdb/v2/ping", r.URL.Path)
+		io.WriteString(w, `{"ping":"ok"}`)
+	}))
+	defer okServer.Close()
+	u, err = url.Parse(okServer.URL)
+	assert.Nil(t, err)
+	c.BaseURL = u
+	_, err = c.Account.Ping(context.Background())
+	assert.Nil(t, err)
+}
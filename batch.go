@@ -0,0 +1,140 @@
+package dnsdb
+
+// Imports
+import (
+	"context"
+	"iter"
+	"sync"
+)
+
+// defaultBatchWorkers is the number of concurrent queries when BatchOptions.Workers is unset
+const defaultBatchWorkers = 4
+
+// BatchService runs many queries concurrently.
+// Every query is sent through the Client, so the Limiter, Cache and RetryTransport of the client all apply.
+type BatchService service
+
+// BatchOptions specifies the optional parameters to the BatchService methods
+type BatchOptions struct {
+	// Workers is the maximum number of queries in flight at once, defaults to 4
+	Workers int
+}
+
+// BatchResult is the outcome of a single query of a batch
+type BatchResult struct {
+	Index int   // Position of the query in the batch
+	Line  int   // Line number in the batch file, only set by RunLines and RunFile
+	Query Query // The query itself
+
+	// RRSets or RData holds the results depending on the Mode of the query, or Summary for summarize queries.
+	// Summary is nil if there were no results.
+	RRSets  []RRSet
+	RData   []RData
+	Summary *Summary
+
+	Response *Response
+	Err      error
+}
+
+// Stream runs the queries with a bounded pool of workers and yields each result as soon as it completes,
+// so results arrive out of order and are tagged with the Index of their query.
+// Stopping the iteration early cancels any queries still in flight.
+func (s *BatchService) Stream(ctx context.Context, queries []Query, opt *BatchOptions) iter.Seq[BatchResult] {
+	workers := defaultBatchWorkers
+	if opt != nil && opt.Workers > 0 {
+		workers = opt.Workers
+	}
+	workers = min(workers, len(queries))
+
+	return func(yield func(BatchResult) bool) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		jobs := make(chan int)
+		results := make(chan BatchResult)
+		var wg sync.WaitGroup
+		for range workers {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := range jobs {
+					res := BatchResult{Index: i, Query: queries[i]}
+					if queries[i].Summarize {
+						res.Summary, res.Response, res.Err = s.client.SummarizeQuery(ctx, queries[i])
+					} else {
+						res.RRSets, res.RData, res.Response, res.Err = queries[i].Lookup(ctx, s.client)
+					}
+					results <- res
+				}
+			}()
+		}
+		go func() {
+			defer close(jobs)
+			for i := range queries {
+				select {
+				case jobs <- i:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+		go func() {
+			wg.Wait()
+			close(results)
+		}()
+		// Let the workers finish if the caller stops early
+		defer func() {
+			cancel()
+			for range results {
+			}
+		}()
+
+		for res := range results {
+			if !yield(res) {
+				return
+			}
+		}
+	}
+}
+
+// StreamOrdered runs the queries like Stream but yields the results in the order of the queries,
+// holding back results which complete early. A query which was never started because ctx was done has
+// the context error as its Err.
+func (s *BatchService) StreamOrdered(ctx context.Context, queries []Query, opt *BatchOptions) iter.Seq[BatchResult] {
+	return func(yield func(BatchResult) bool) {
+		pending := make(map[int]BatchResult)
+		next := 0
+		for res := range s.Stream(ctx, queries, opt) {
+			pending[res.Index] = res
+			for {
+				res, ok := pending[next]
+				if !ok {
+					break
+				}
+				delete(pending, next)
+				next++
+				if !yield(res) {
+					return
+				}
+			}
+		}
+		for ; next < len(queries); next++ {
+			res, ok := pending[next]
+			if !ok {
+				res = BatchResult{Index: next, Query: queries[next], Err: ctx.Err()}
+			}
+			if !yield(res) {
+				return
+			}
+		}
+	}
+}
+
+// Run runs the queries like StreamOrdered and returns every result in the order of the queries
+func (s *BatchService) Run(ctx context.Context, queries []Query, opt *BatchOptions) []BatchResult {
+	results := make([]BatchResult, 0, len(queries))
+	for res := range s.StreamOrdered(ctx, queries, opt) {
+		results = append(results, res)
+	}
+	return results
+}
@@ -0,0 +1,118 @@
+package dnsdb
+
+import (
+	"github.com/stretchr/testify/assert"
+
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func Test_BatchService_Run(t *testing.T) {
+	// Setup a server which echoes the queried value and tracks concurrency
+	var inflight, peak int32
+	var mu sync.Mutex
+	paths := map[string]bool{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inflight, 1)
+		defer atomic.AddInt32(&inflight, -1)
+		mu.Lock()
+		paths[r.URL.Path] = true
+		if n > peak {
+			peak = n
+		}
+		mu.Unlock()
+		time.Sleep(10 * time.Millisecond)
+
+		parts := strings.Split(r.URL.Path, "/")
+		switch parts[2] {
+		case "rrset":
+			io.WriteString(w, `{"rrname":"`+parts[4]+`.","rrtype":"A","rdata":["192.0.2.1"]}`)
+		case "rdata":
+			io.WriteString(w, `{"rrname":"example.com.","rrtype":"A","rdata":"`+parts[4]+`"}`)
+		}
+	}))
+	defer server.Close()
+	c := NewClient(nil)
+	u, err := url.Parse(server.URL + "/")
+	assert.Nil(t, err)
+	c.BaseURL = u
+
+	queries := []Query{
+		{Mode: QueryRRSet, Kind: QueryName, Value: "a.example.com", RRType: RRTypeA},
+		{Mode: QueryRData, Kind: QueryIP, Value: "192.0.2.1"},
+		{Mode: QueryRData, Kind: QueryIP, Value: "192.0.2.0,24"},
+		{Mode: QueryRData, Kind: QueryRaw, Value: "c0000201"},
+		{Mode: QueryRData, Kind: QueryName, Value: "b.example.com"},
+		{Mode: QueryRData, Kind: QueryRaw, Value: "zz"},
+		{Mode: QueryRRSet, Kind: QueryName, Value: "c.example.com"},
+	}
+
+	// Verify that results are returned in input order with per-query errors
+	results := c.Batch.Run(context.Background(), queries, &BatchOptions{Workers: 2})
+	assert.Len(t, results, len(queries))
+	for i, res := range results {
+		assert.Equal(t, i, res.Index)
+		assert.Equal(t, queries[i], res.Query)
+	}
+	assert.Nil(t, results[0].Err)
+	assert.Equal(t, "a.example.com.", *results[0].RRSets[0].RRName)
+	assert.Equal(t, "192.0.2.1", *results[1].RData[0].RData)
+	assert.Equal(t, "192.0.2.0,24", *results[2].RData[0].RData)
+	assert.Equal(t, "c0000201", *results[3].RData[0].RData)
+	assert.Equal(t, "b.example.com", *results[4].RData[0].RData)
+	assert.NotNil(t, results[5].Err)
+	assert.Equal(t, "c.example.com.", *results[6].RRSets[0].RRName)
+
+	// Verify that the worker pool was bounded and every kind of lookup was sent
+	assert.LessOrEqual(t, peak, int32(2))
+	assert.True(t, paths["/lookup/rdata/ip/192.0.2.0,24"])
+	assert.True(t, paths["/lookup/rrset/name/a.example.com/A"])
+}
+
+func Test_BatchService_Stream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"rrname":"example.com.","rrtype":"A","rdata":["192.0.2.1"]}`)
+	}))
+	defer server.Close()
+	c := NewClient(nil)
+	u, err := url.Parse(server.URL + "/")
+	assert.Nil(t, err)
+	c.BaseURL = u
+
+	queries := make([]Query, 20)
+	for i := range queries {
+		queries[i] = Query{Mode: QueryRRSet, Kind: QueryName, Value: "example.com"}
+	}
+
+	// Verify that every result is yielded exactly once
+	seen := map[int]bool{}
+	for res := range c.Batch.Stream(context.Background(), queries, nil) {
+		assert.Nil(t, res.Err)
+		assert.False(t, seen[res.Index])
+		seen[res.Index] = true
+	}
+	assert.Len(t, seen, len(queries))
+
+	// Verify that stopping early returns promptly
+	count := 0
+	for range c.Batch.Stream(context.Background(), queries, &BatchOptions{Workers: 3}) {
+		count++
+		break
+	}
+	assert.Equal(t, 1, count)
+
+	// Verify that a cancelled context fails the queries which never ran
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for _, res := range c.Batch.Run(ctx, queries, nil) {
+		assert.ErrorIs(t, res.Err, context.Canceled)
+	}
+}
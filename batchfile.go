@@ -0,0 +1,302 @@
+package dnsdb
+
+// Imports
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"iter"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseBatchLine parses a query written in the dnsdbq batch file syntax, such as "rrset/name/example.com/A/example.com"
+// or "rdata/ip/192.0.2.0,24". Per-query options are set by $options lines, see ParseBatchLines.
+func ParseBatchLine(line string) (Query, error) {
+	var q Query
+	if line == "" || strings.ContainsAny(line, " \t") {
+		return q, fmt.Errorf("dnsdb: invalid batch query %q", line)
+	}
+	segments := strings.Split(line, "/")
+	if len(segments) < 3 || segments[2] == "" {
+		return q, fmt.Errorf("dnsdb: invalid batch query %q", line)
+	}
+	q.Mode, q.Kind, q.Value = QueryMode(segments[0]), QueryKind(segments[1]), segments[2]
+	max := 4
+	switch {
+	case q.Mode == QueryRRSet && q.Kind == QueryName:
+		max = 5
+	case q.Mode == QueryRData && q.Kind == QueryIP:
+		max = 3
+	case q.Mode == QueryRData && (q.Kind == QueryName || q.Kind == QueryRaw):
+	default:
+		return q, fmt.Errorf("dnsdb: invalid batch query %q", line)
+	}
+	if len(segments) > max {
+		return q, fmt.Errorf("dnsdb: invalid batch query %q", line)
+	}
+	if len(segments) >= 4 {
+		q.RRType = RRType(segments[3])
+		if err := q.RRType.Validate(); err != nil {
+			return q, err
+		}
+	}
+	if len(segments) == 5 {
+		q.Bailiwick = segments[4]
+	}
+	return q, nil
+}
+
+// batchOptionsPrefix starts a line of a batch file which sets the options of the following queries
+const batchOptionsPrefix = "$options"
+
+// parseBatchOptions parses the arguments of a $options line: -l limit, -O offset, -A after and -B before
+// (any time accepted by ParseTime) and -c for a strict time fence.
+func parseBatchOptions(args []string) (LookupOptions, error) {
+	var opt LookupOptions
+	var after, before string
+	var strict bool
+	for i := 0; i < len(args); i++ {
+		flag := args[i]
+		if flag == "-c" {
+			strict = true
+			continue
+		}
+		if i+1 >= len(args) {
+			return opt, fmt.Errorf("dnsdb: batch option %s requires a value", flag)
+		}
+		i++
+		var err error
+		switch flag {
+		case "-l":
+			opt.Limit, err = strconv.ParseInt(args[i], 10, 64)
+		case "-O":
+			opt.Offset, err = strconv.ParseInt(args[i], 10, 64)
+		case "-A":
+			after = args[i]
+		case "-B":
+			before = args[i]
+		default:
+			return opt, fmt.Errorf("dnsdb: unknown batch option %s", flag)
+		}
+		if err != nil {
+			return opt, fmt.Errorf("dnsdb: invalid batch option %s: %w", flag, err)
+		}
+	}
+	fence, err := NewTimeFence(after, before, strict)
+	if err != nil {
+		return opt, err
+	}
+	return fence.Apply(opt), nil
+}
+
+// BatchFileLine is a query line of a dnsdbq batch file as parsed by ParseBatchLines
+type BatchFileLine struct {
+	Number int    // Line number in the file, starting at 1
+	Text   string // The line without surrounding whitespace
+	Query  Query  // The parsed query, only valid if Err is nil
+	Err    error  // Why the line could not be parsed
+}
+
+// ParseBatchLines parses every query line of a dnsdbq batch file with ParseBatchLine, skipping blank lines and # comments.
+// As in dnsdbq's -ff mode a "$options" line sets the limit, offset and time fence of the queries after it, using the
+// same -l, -O, -A, -B and -c flags as dnsdbq, until the next $options line. A bare "$options" line clears them.
+//
+// A line which does not parse has its Err set and the following lines are still parsed. An invalid $options line
+// is returned with its Err set and the queries after it fail until the next $options line, rather than running
+// without the options. The returned error is only set if r could not be read.
+func ParseBatchLines(r io.Reader) ([]BatchFileLine, error) {
+	var lines []BatchFileLine
+	var opt LookupOptions
+	var optErr error // Set if the last $options line was invalid
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		if fields := strings.Fields(text); fields[0] == batchOptionsPrefix {
+			var err error
+			if opt, err = parseBatchOptions(fields[1:]); err != nil {
+				optErr = fmt.Errorf("dnsdb: invalid $options on line %d", n)
+				lines = append(lines, BatchFileLine{Number: n, Text: text, Err: err})
+			} else {
+				optErr = nil
+			}
+			continue
+		}
+		q, err := ParseBatchLine(text)
+		if err == nil {
+			q.LookupOptions, err = opt, optErr
+		}
+		lines = append(lines, BatchFileLine{Number: n, Text: text, Query: q, Err: err})
+	}
+	return lines, scanner.Err()
+}
+
+// ParseBatch parses a dnsdbq batch file like ParseBatchLines but fails on the first line which does not parse
+func ParseBatch(r io.Reader) ([]Query, error) {
+	lines, err := ParseBatchLines(r)
+	if err != nil {
+		return nil, err
+	}
+	queries := make([]Query, 0, len(lines))
+	for _, line := range lines {
+		if line.Err != nil {
+			return nil, fmt.Errorf("line %d: %w", line.Number, line.Err)
+		}
+		queries = append(queries, line.Query)
+	}
+	return queries, nil
+}
+
+// batchQuery renders the query without its options in the syntax accepted by ParseBatchLine
+func (q Query) batchQuery() (string, error) {
+	if q.Summarize || q.Kind.flex() {
+		return "", fmt.Errorf("dnsdb: batch syntax cannot express summarize or flex queries")
+	}
+	if (q.Mode != QueryRRSet && q.Bailiwick != "") || (q.Kind == QueryIP && q.RRType != "") {
+		return "", fmt.Errorf("dnsdb: batch syntax cannot express %s/%s with that rrtype or bailiwick", q.Mode, q.Kind)
+	}
+	line := string(q.Mode) + "/" + string(q.Kind) + "/" + q.Value
+	if q.Bailiwick != "" && q.RRType == "" {
+		line += "/" + string(RRTypeANY)
+	} else if q.RRType != "" {
+		line += "/" + string(q.RRType)
+	}
+	if q.Bailiwick != "" {
+		line += "/" + q.Bailiwick
+	}
+	return line, nil
+}
+
+// batchOptions renders the lookup options as the flags of a $options line with times as unix seconds,
+// returning the empty string if there are none
+func (opt LookupOptions) batchOptions() (string, error) {
+	var flags []string
+	if opt.Limit != 0 {
+		flags = append(flags, "-l", strconv.FormatInt(opt.Limit, 10))
+	}
+	if opt.Offset != 0 {
+		flags = append(flags, "-O", strconv.FormatInt(opt.Offset, 10))
+	}
+	unix := func(t time.Time) string { return strconv.FormatInt(t.Unix(), 10) }
+	strict := !opt.TimeFirstAfter.IsZero() || !opt.TimeLastBefore.IsZero()
+	loose := !opt.TimeLastAfter.IsZero() || !opt.TimeFirstBefore.IsZero()
+	switch {
+	case strict && loose:
+		return "", fmt.Errorf("dnsdb: batch syntax cannot mix strict and loose time fences")
+	case strict:
+		flags = append(flags, "-c")
+		if !opt.TimeFirstAfter.IsZero() {
+			flags = append(flags, "-A", unix(opt.TimeFirstAfter))
+		}
+		if !opt.TimeLastBefore.IsZero() {
+			flags = append(flags, "-B", unix(opt.TimeLastBefore))
+		}
+	case loose:
+		if !opt.TimeLastAfter.IsZero() {
+			flags = append(flags, "-A", unix(opt.TimeLastAfter))
+		}
+		if !opt.TimeFirstBefore.IsZero() {
+			flags = append(flags, "-B", unix(opt.TimeFirstBefore))
+		}
+	}
+	opt.Limit, opt.Offset = 0, 0
+	opt.TimeFirstAfter, opt.TimeFirstBefore, opt.TimeLastAfter, opt.TimeLastBefore = time.Time{}, time.Time{}, time.Time{}, time.Time{}
+	// Client identification is not part of the query
+	opt.SWClient, opt.Version = "", ""
+	if opt != (LookupOptions{}) {
+		return "", fmt.Errorf("dnsdb: batch syntax cannot express all lookup options")
+	}
+	return strings.Join(flags, " "), nil
+}
+
+// BatchLine renders the query in the dnsdbq batch file syntax accepted by ParseBatchLines. A query with a limit,
+// offset or time fence is preceded by a $options line, which also applies to any queries written after it, use
+// WriteBatch to write several queries. It returns an error if the query uses options which the syntax cannot express.
+func (q Query) BatchLine() (string, error) {
+	line, err := q.batchQuery()
+	if err != nil {
+		return "", err
+	}
+	flags, err := q.LookupOptions.batchOptions()
+	if err != nil {
+		return "", err
+	}
+	if flags != "" {
+		line = batchOptionsPrefix + " " + flags + "\n" + line
+	}
+	return line, nil
+}
+
+// WriteBatch writes the queries as a dnsdbq batch file which ParseBatchLines reads back, with a $options line
+// wherever the options change from those of the previous query
+func WriteBatch(w io.Writer, queries []Query) error {
+	bw := bufio.NewWriter(w)
+	var current string
+	for _, q := range queries {
+		line, err := q.batchQuery()
+		if err != nil {
+			return err
+		}
+		flags, err := q.LookupOptions.batchOptions()
+		if err != nil {
+			return err
+		}
+		if flags != current {
+			current = flags
+			bw.WriteString(strings.TrimSpace(batchOptionsPrefix+" "+flags) + "\n")
+		}
+		bw.WriteString(line + "\n")
+	}
+	return bw.Flush()
+}
+
+// RunLines runs the lines of a batch file like StreamOrdered, yielding one result per line in order with its Index
+// into lines and its Line number set. A line which did not parse is not run, its result has the parse error as Err.
+func (s *BatchService) RunLines(ctx context.Context, lines []BatchFileLine, opt *BatchOptions) iter.Seq[BatchResult] {
+	return func(yield func(BatchResult) bool) {
+		var queries []Query
+		var indexes []int // The index into lines of each query
+		for i, line := range lines {
+			if line.Err == nil {
+				queries = append(queries, line.Query)
+				indexes = append(indexes, i)
+			}
+		}
+		// Yield the lines before upto which did not parse
+		next := 0
+		failed := func(upto int) bool {
+			for ; next < upto; next++ {
+				if !yield(BatchResult{Index: next, Line: lines[next].Number, Query: lines[next].Query, Err: lines[next].Err}) {
+					return false
+				}
+			}
+			return true
+		}
+		for res := range s.StreamOrdered(ctx, queries, opt) {
+			i := indexes[res.Index]
+			if !failed(i) {
+				return
+			}
+			res.Index, res.Line, next = i, lines[i].Number, i+1
+			if !yield(res) {
+				return
+			}
+		}
+		failed(len(lines))
+	}
+}
+
+// RunFile parses a dnsdbq batch file with ParseBatchLines and runs every line with RunLines,
+// so the results are yielded one per line in order. The error is only set if r could not be read.
+func (s *BatchService) RunFile(ctx context.Context, r io.Reader, opt *BatchOptions) (iter.Seq[BatchResult], error) {
+	lines, err := ParseBatchLines(r)
+	if err != nil {
+		return nil, err
+	}
+	return s.RunLines(ctx, lines, opt), nil
+}
@@ -0,0 +1,173 @@
+package dnsdb
+
+import (
+	"github.com/stretchr/testify/assert"
+
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_ParseBatchLine(t *testing.T) {
+	// Verify that each query form parses
+	q, err := ParseBatchLine("rrset/name/example.com/A/com")
+	assert.Nil(t, err)
+	assert.Equal(t, Query{Mode: QueryRRSet, Kind: QueryName, Value: "example.com", RRType: RRTypeA, Bailiwick: "com"}, q)
+	q, err = ParseBatchLine("rdata/ip/1.2.3.0,24")
+	assert.Nil(t, err)
+	assert.Equal(t, Query{Mode: QueryRData, Kind: QueryIP, Value: "1.2.3.0,24"}, q)
+	q, err = ParseBatchLine("rdata/raw/0a0b/MX")
+	assert.Nil(t, err)
+	assert.Equal(t, Query{Mode: QueryRData, Kind: QueryRaw, Value: "0a0b", RRType: RRTypeMX}, q)
+
+	// Verify that malformed lines fail, options are only accepted on $options lines
+	for _, line := range []string{
+		"", "rrset/name", "rrset/ip/1.2.3.4", "rdata/ip/1.2.3.4/A", "rrset/name/a/A/b/c", "rdata/name/a/BOGUS",
+		"rrset/name/a -l 10",
+	} {
+		_, err := ParseBatchLine(line)
+		assert.NotNil(t, err, line)
+	}
+}
+
+func Test_ParseBatchLines(t *testing.T) {
+	// Verify that $options lines apply to the queries after them, as in dnsdbq's -ff mode
+	lines, err := ParseBatchLines(strings.NewReader(`# dnsdbq -ff batch
+rrset/name/fsi.io
+$options -l 10 -O 5 -A 1000 -B 2000
+rdata/name/hq.fsi.io
+rrset/name/*.farsightsecurity.com/A
+$options -c -A -1d
+rdata/ip/66.160.140.0,24
+$options
+rdata/raw/0a0b/MX
+`))
+	assert.Nil(t, err)
+	assert.Len(t, lines, 5)
+	assert.Equal(t, []int{2, 4, 5, 7, 9}, []int{lines[0].Number, lines[1].Number, lines[2].Number, lines[3].Number, lines[4].Number})
+	for _, line := range lines {
+		assert.Nil(t, line.Err, line.Text)
+	}
+	assert.Equal(t, LookupOptions{}, lines[0].Query.LookupOptions)
+	fenced := LookupOptions{Limit: 10, Offset: 5, TimeLastAfter: time.Unix(1000, 0), TimeFirstBefore: time.Unix(2000, 0)}
+	assert.Equal(t, fenced, lines[1].Query.LookupOptions)
+	assert.Equal(t, fenced, lines[2].Query.LookupOptions)
+	assert.Equal(t, Query{Mode: QueryRRSet, Kind: QueryName, Value: "*.farsightsecurity.com", RRType: RRTypeA, LookupOptions: fenced}, lines[2].Query)
+	assert.WithinDuration(t, time.Now().Add(-24*time.Hour), lines[3].Query.TimeFirstAfter, time.Minute)
+	assert.Equal(t, LookupOptions{}, lines[4].Query.LookupOptions)
+
+	// Verify that an invalid $options line fails along with the queries it would apply to
+	for _, options := range []string{"$options -l", "$options -l ten", "$options -x 1", "$options -A whenever"} {
+		lines, err = ParseBatchLines(strings.NewReader("rrset/name/a\n" + options + "\nrrset/name/b\n$options -l 1\nrrset/name/c\n"))
+		assert.Nil(t, err)
+		assert.Len(t, lines, 4, options)
+		assert.Nil(t, lines[0].Err)
+		assert.NotNil(t, lines[1].Err, options)
+		assert.Equal(t, options, lines[1].Text)
+		assert.EqualError(t, lines[2].Err, "dnsdb: invalid $options on line 2")
+		assert.Nil(t, lines[3].Err)
+		assert.Equal(t, int64(1), lines[3].Query.Limit)
+	}
+}
+
+func Test_Query_BatchLine(t *testing.T) {
+	// Verify that queries round trip, with their options on a preceding $options line
+	for _, line := range []string{
+		"rrset/name/example.com",
+		"rrset/name/example.com/ANY/com",
+		"$options -l 10 -O 5\nrdata/ip/1.2.3.0,24",
+		"$options -A 1000 -B 2000\nrdata/raw/0a0b/MX",
+		"$options -c -A 1000\nrdata/name/hq.fsi.io",
+	} {
+		queries, err := ParseBatch(strings.NewReader(line))
+		assert.Nil(t, err)
+		assert.Len(t, queries, 1)
+		actual, err := queries[0].BatchLine()
+		assert.Nil(t, err)
+		assert.Equal(t, line, actual)
+	}
+
+	// Verify that a bailiwick without an rrtype is rendered with ANY
+	actual, err := Query{Mode: QueryRRSet, Kind: QueryName, Value: "example.com", Bailiwick: "com"}.BatchLine()
+	assert.Nil(t, err)
+	assert.Equal(t, "rrset/name/example.com/ANY/com", actual)
+
+	// Verify that inexpressible queries fail
+	q := Query{Mode: QueryRRSet, Kind: QueryName, Value: "example.com"}
+	q.TimeFirstAfter, q.TimeFirstBefore = time.Unix(1000, 0), time.Unix(2000, 0)
+	_, err = q.BatchLine()
+	assert.NotNil(t, err)
+	q = Query{Mode: QueryRRSet, Kind: QueryName, Value: "example.com"}
+	q.HumanTime = true
+	_, err = q.BatchLine()
+	assert.NotNil(t, err)
+	_, err = Query{Mode: QueryRData, Kind: QueryIP, Value: "1.2.3.4", RRType: RRTypeA}.BatchLine()
+	assert.NotNil(t, err)
+}
+
+func Test_WriteBatch(t *testing.T) {
+	// Verify that $options lines are written only where the options change and the file round trips
+	batch := `rrset/name/fsi.io
+$options -l 10
+rdata/name/hq.fsi.io
+rrset/name/*.farsightsecurity.com/A
+$options -c -A 1000
+rdata/ip/66.160.140.0,24
+$options
+rdata/raw/0a0b/MX
+`
+	queries, err := ParseBatch(strings.NewReader(batch))
+	assert.Nil(t, err)
+	var buf bytes.Buffer
+	assert.Nil(t, WriteBatch(&buf, queries))
+	assert.Equal(t, batch, buf.String())
+
+	// Verify that an inexpressible query fails
+	assert.NotNil(t, WriteBatch(&buf, []Query{{Mode: QueryRRSet, Kind: QueryName, Value: "example.com", Summarize: true}}))
+}
+
+func Test_BatchService_RunFile(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		parts := strings.Split(r.URL.Path, "/")
+		io.WriteString(w, `{"rrname":"`+parts[4]+`.","rrtype":"A","rdata":"192.0.2.1"}`)
+	}))
+	defer server.Close()
+	c := NewClient(nil)
+	u, err := url.Parse(server.URL + "/")
+	assert.Nil(t, err)
+	c.BaseURL = u
+
+	// Verify that results are yielded per line in order
+	seq, err := c.Batch.RunFile(context.Background(), strings.NewReader("# names\nrdata/name/a.example\n\n$options -l 1\nrdata/name/b.example\nrdata/name/c.example\n"), nil)
+	assert.Nil(t, err)
+	var names []string
+	for res := range seq {
+		assert.Nil(t, res.Err)
+		names = append(names, *res.RData[0].RRName)
+	}
+	assert.Equal(t, []string{"a.example.", "b.example.", "c.example."}, names)
+
+	// Verify that a malformed line reports its line number and the other lines still run
+	seq, err = c.Batch.RunFile(context.Background(), strings.NewReader("rdata/name/a.example\n\nrdata/bogus/b\nrdata/name/c.example\n"), nil)
+	assert.Nil(t, err)
+	var results []BatchResult
+	for res := range seq {
+		results = append(results, res)
+	}
+	assert.Len(t, results, 3)
+	assert.Equal(t, []int{0, 1, 2}, []int{results[0].Index, results[1].Index, results[2].Index})
+	assert.Equal(t, []int{1, 3, 4}, []int{results[0].Line, results[1].Line, results[2].Line})
+	assert.Nil(t, results[0].Err)
+	assert.EqualError(t, results[1].Err, `dnsdb: invalid batch query "rdata/bogus/b"`)
+	assert.Equal(t, "c.example.", *results[2].RData[0].RRName)
+
+	// Verify that ParseBatch still fails on the first malformed line
+	_, err = ParseBatch(strings.NewReader("rdata/name/a.example\nrdata/bogus/b\n"))
+	assert.EqualError(t, err, `line 2: dnsdb: invalid batch query "rdata/bogus/b"`)
+}
@@ -0,0 +1,176 @@
+package dnsdb
+
+// Imports
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"iter"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ErrCacheMiss is returned in CacheOnly mode when a query is not in the cache
+var ErrCacheMiss = errors.New("dnsdb: query not in cache")
+
+// CacheMode controls how a DiskCache is used
+type CacheMode int
+
+const (
+	CacheDefault CacheMode = iota // Use fresh cached results, otherwise query the API and cache the results
+	CacheOnly                     // Never query the API, queries which are not cached fail with ErrCacheMiss
+	CacheRefresh                  // Always query the API and replace the cached results
+)
+
+// DiskCache stores the decoded results of queries on local disk so repeated queries do not spend quota.
+// Queries are keyed on the request URL, which includes the path and every encoded LookupOptions parameter.
+// Only complete result streams are stored, a stream which fails or is stopped early is not.
+type DiskCache struct {
+	Dir  string        // Directory the results are stored in, created if needed
+	TTL  time.Duration // How long results are fresh for, forever if zero
+	Mode CacheMode
+}
+
+// cacheLine is a single line of a cached result, every object followed by a final line with the condition
+type cacheLine[T any] struct {
+	Obj  *T        `json:"obj,omitempty"`
+	End  bool      `json:"end,omitempty"`
+	Cond Condition `json:"cond,omitempty"`
+	Msg  string    `json:"msg,omitempty"`
+}
+
+// path returns the file the results of the request are stored in
+func (d *DiskCache) path(req *http.Request) string {
+	h := sha256.New()
+	io.WriteString(h, req.URL.Host+req.URL.EscapedPath()+"?"+req.URL.Query().Encode())
+	return filepath.Join(d.Dir, hex.EncodeToString(h.Sum(nil))+".ndjson")
+}
+
+// open returns the file of a cached result if there is a fresh one
+func (d *DiskCache) open(name string) (*os.File, error) {
+	f, err := os.Open(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrCacheMiss
+	} else if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	if d.TTL > 0 && time.Since(info.ModTime()) > d.TTL {
+		f.Close()
+		return nil, ErrCacheMiss
+	}
+	return f, nil
+}
+
+// readCache returns an iterator over the cached results in f, recording the condition on resp once it is read
+func readCache[T any](ctx context.Context, f *os.File, resp *Response) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		defer f.Close()
+		dec := json.NewDecoder(f)
+		for {
+			var zero T
+			if err := ctx.Err(); err != nil {
+				yield(zero, err)
+				return
+			}
+			var line cacheLine[T]
+			if err := dec.Decode(&line); err == io.EOF {
+				yield(zero, ErrTruncated)
+				return
+			} else if err != nil {
+				yield(zero, err)
+				return
+			}
+			if line.End {
+				resp.Condition = line.Cond
+				resp.Message = line.Msg
+				return
+			}
+			if line.Obj != nil && !yield(*line.Obj, nil) {
+				return
+			}
+		}
+	}
+}
+
+// writeCache returns an iterator which stores the results of seq in name once they have all been read.
+// Failing to write the cache does not fail the stream.
+func writeCache[T any](seq iter.Seq2[T, error], resp *Response, name string) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var f *os.File
+		err := os.MkdirAll(filepath.Dir(name), 0o755)
+		if err == nil {
+			f, err = os.CreateTemp(filepath.Dir(name), ".tmp-*")
+		}
+		if err != nil {
+			for r, err := range seq {
+				if !yield(r, err) {
+					return
+				}
+			}
+			return
+		}
+
+		enc := json.NewEncoder(f)
+		complete := false
+		defer func() {
+			if f.Close() == nil && complete && os.Rename(f.Name(), name) == nil {
+				return
+			}
+			os.Remove(f.Name())
+		}()
+		var werr error
+		for r, err := range seq {
+			if err == nil && werr == nil {
+				werr = enc.Encode(cacheLine[T]{Obj: &r})
+			}
+			if !yield(r, err) || err != nil {
+				return
+			}
+		}
+		if werr == nil {
+			werr = enc.Encode(cacheLine[T]{End: true, Cond: resp.Condition, Msg: resp.Message})
+		}
+		complete = werr == nil
+	}
+}
+
+// streamCached is a helper function that serves the results of req from the cache, calling fetch to query the API when needed
+func streamCached[T any](ctx context.Context, d *DiskCache, req *http.Request, fetch func() (iter.Seq2[T, error], *Response, error)) (iter.Seq2[T, error], *Response, error) {
+	name := d.path(req)
+	if d.Mode != CacheRefresh {
+		f, err := d.open(name)
+		if err == nil {
+			resp := &Response{
+				Response: &http.Response{
+					Status:     "200 OK",
+					StatusCode: http.StatusOK,
+					Header:     http.Header{},
+					Body:       f,
+					Request:    req,
+				},
+				Cached: true,
+			}
+			return readCache[T](ctx, f, resp), resp, nil
+		}
+		if d.Mode == CacheOnly {
+			return nil, nil, err
+		}
+	}
+
+	seq, resp, err := fetch()
+	if err != nil {
+		return nil, resp, err
+	}
+	return writeCache(seq, resp, name), resp, nil
+}
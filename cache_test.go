@@ -0,0 +1,122 @@
+package dnsdb
+
+import (
+	"github.com/stretchr/testify/assert"
+
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func Test_DiskCache(t *testing.T) {
+	// Setup a client with a cache
+	c := NewClient(nil)
+	c.Cache = &DiskCache{Dir: filepath.Join(t.TempDir(), "cache")}
+
+	// A server which counts the requests it serves
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		io.WriteString(w, `{"count":45644,"time_first":1372706073,"time_last":1468330740,"rrname":"fsi.io.","rrtype":"MX","rdata":"10 hq.fsi.io."}
+{"count":19304,"time_first":1374098929,"time_last":1468333042,"rrname":"farsightsecurity.com.","rrtype":"MX","rdata":"10 hq.fsi.io."}`)
+	}))
+	defer server.Close()
+	u, err := url.Parse(server.URL)
+	assert.Nil(t, err)
+	c.BaseURL = u
+	opt := &RDataLookupNameOptions{RRType: "MX"}
+
+	// Verify that the first lookup queries the API
+	expected, resp, err := c.RData.LookupName(context.Background(), "hq.fsi.io", opt)
+	assert.Nil(t, err)
+	assert.False(t, resp.Cached)
+	assert.Len(t, expected, 2)
+	assert.Equal(t, 1, requests)
+
+	// Verify that the second lookup is served from the cache
+	actual, resp, err := c.RData.LookupName(context.Background(), "hq.fsi.io", opt)
+	assert.Nil(t, err)
+	assert.True(t, resp.Cached)
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, expected, actual)
+	assert.Equal(t, 1, requests)
+
+	// Verify that different options are a different query
+	_, resp, err = c.RData.LookupName(context.Background(), "hq.fsi.io", &RDataLookupNameOptions{RRType: "MX", LookupOptions: LookupOptions{Limit: 1}})
+	assert.Nil(t, err)
+	assert.False(t, resp.Cached)
+	assert.Equal(t, 2, requests)
+
+	// Verify that a stream stopped early is not cached
+	seq, resp, err := c.RData.StreamName(context.Background(), "fsi.io", nil)
+	assert.Nil(t, err)
+	for range seq {
+		break
+	}
+	_, resp, err = c.RData.LookupName(context.Background(), "fsi.io", nil)
+	assert.Nil(t, err)
+	assert.False(t, resp.Cached)
+	assert.Equal(t, 4, requests)
+
+	// Verify that refresh mode always queries the API
+	c.Cache.Mode = CacheRefresh
+	_, resp, err = c.RData.LookupName(context.Background(), "hq.fsi.io", opt)
+	assert.Nil(t, err)
+	assert.False(t, resp.Cached)
+	assert.Equal(t, 5, requests)
+
+	// Verify that cache only mode never queries the API
+	c.Cache.Mode = CacheOnly
+	actual, resp, err = c.RData.LookupName(context.Background(), "hq.fsi.io", opt)
+	assert.Nil(t, err)
+	assert.True(t, resp.Cached)
+	assert.Equal(t, expected, actual)
+	_, _, err = c.RData.LookupName(context.Background(), "www.fsi.io", opt)
+	assert.Equal(t, ErrCacheMiss, err)
+	assert.Equal(t, 5, requests)
+
+	// Verify that expired entries are misses
+	c.Cache.TTL = time.Minute
+	entries, err := os.ReadDir(c.Cache.Dir)
+	assert.Nil(t, err)
+	for _, entry := range entries {
+		old := time.Now().Add(-time.Hour)
+		assert.Nil(t, os.Chtimes(filepath.Join(c.Cache.Dir, entry.Name()), old, old))
+	}
+	_, _, err = c.RData.LookupName(context.Background(), "hq.fsi.io", opt)
+	assert.Equal(t, ErrCacheMiss, err)
+}
+
+func Test_DiskCache_Condition(t *testing.T) {
+	// Setup a client speaking APIv2 with a cache
+	c := NewClient(nil)
+	c.APIVersion = APIv2
+	c.Cache = &DiskCache{Dir: t.TempDir()}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"cond":"begin"}
+{"obj":{"count":45644,"rrname":"fsi.io.","rrtype":"MX","rdata":"10 hq.fsi.io."}}
+{"cond":"limited","msg":"Result limit reached"}
+`)
+	}))
+	defer server.Close()
+	u, err := url.Parse(server.URL)
+	assert.Nil(t, err)
+	c.BaseURL = u
+
+	// Verify that the condition of a cached result is restored
+	_, _, err = c.RData.LookupName(context.Background(), "hq.fsi.io", nil)
+	assert.Nil(t, err)
+	actual, resp, err := c.RData.LookupName(context.Background(), "hq.fsi.io", nil)
+	assert.Nil(t, err)
+	assert.True(t, resp.Cached)
+	assert.Len(t, actual, 1)
+	assert.Equal(t, ConditionLimited, resp.Condition)
+	assert.Equal(t, "Result limit reached", resp.Message)
+}
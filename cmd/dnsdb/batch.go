@@ -0,0 +1,76 @@
+package main
+
+// Imports
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/bored-engineer/go-dnsdb"
+)
+
+// withDefaults fills the options which no $options line set for a batch line from the command line flags
+func withDefaults(q dnsdb.Query, defaults dnsdb.LookupOptions) dnsdb.Query {
+	if q.Limit == 0 {
+		q.Limit = defaults.Limit
+	}
+	if q.Offset == 0 {
+		q.Offset = defaults.Offset
+	}
+	if q.TimeFirstBefore.IsZero() && q.TimeFirstAfter.IsZero() && q.TimeLastBefore.IsZero() && q.TimeLastAfter.IsZero() {
+		q.TimeFirstBefore, q.TimeFirstAfter = defaults.TimeFirstBefore, defaults.TimeFirstAfter
+		q.TimeLastBefore, q.TimeLastAfter = defaults.TimeLastBefore, defaults.TimeLastAfter
+	}
+	q.Aggr, q.HumanTime, q.SWClient, q.Version = defaults.Aggr, defaults.HumanTime, defaults.SWClient, defaults.Version
+	return q
+}
+
+// runBatch runs every line of a dnsdbq batch file, "-" reads from stdin. The results of each line are followed by
+// a "--" separator like dnsdbq. Lines which fail to parse or run are reported to stderr with their line number,
+// and the remaining lines still run.
+func runBatch(ctx context.Context, c *dnsdb.Client, opts *options, stdin io.Reader, stdout io.Writer, out output, stderr io.Writer) error {
+	r := stdin
+	if opts.batch != "-" {
+		f, err := os.Open(opts.batch)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+	lines, err := dnsdb.ParseBatchLines(r)
+	if err != nil {
+		return err
+	}
+	for i := range lines {
+		lines[i].Query = withDefaults(lines[i].Query, opts.lookup)
+	}
+
+	failed := 0
+	for res := range c.Batch.RunLines(ctx, lines, &dnsdb.BatchOptions{Workers: opts.workers}) {
+		if res.Err == nil {
+			if res.Query.Mode == dnsdb.QueryRRSet {
+				res.Err = dnsdb.EncodeRRSets(out, dnsdb.All(res.RRSets))
+			} else {
+				res.Err = dnsdb.EncodeRData(out, dnsdb.All(res.RData))
+			}
+			warnCondition(stderr, res.Response)
+		}
+		// Flush so the separator follows the results of this line
+		if err := out.Flush(); err != nil {
+			return err
+		}
+		if res.Err != nil {
+			failed++
+			fmt.Fprintf(stderr, "line %d: %s: %v\n", res.Line, lines[res.Index].Text, res.Err)
+		}
+		if _, err := io.WriteString(stdout, "--\n"); err != nil {
+			return err
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d queries failed", failed, len(lines))
+	}
+	return nil
+}
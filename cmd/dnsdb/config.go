@@ -0,0 +1,85 @@
+package main
+
+// Imports
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// config is the subset of the dnsdbq configuration file understood by dnsdb
+type config struct {
+	APIKey string
+	Server string
+}
+
+// configPaths returns the default locations of the configuration file, in order of preference
+func configPaths(getenv func(string) string) []string {
+	var paths []string
+	if home := getenv("HOME"); home != "" {
+		paths = append(paths, filepath.Join(home, ".dnsdb-query.conf"))
+	}
+	return append(paths, "/etc/dnsdb-query.conf")
+}
+
+// parseConfig parses shell style KEY=value lines, as written for dnsdbq. Unknown keys and comments are ignored.
+func parseConfig(path string) (config, error) {
+	var cfg config
+	f, err := os.Open(path)
+	if err != nil {
+		return cfg, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(strings.TrimPrefix(line, "export "), "=")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		switch strings.TrimSpace(key) {
+		case "APIKEY", "DNSDB_API_KEY":
+			cfg.APIKey = value
+		case "DNSDB_SERVER":
+			cfg.Server = value
+		}
+	}
+	return cfg, scanner.Err()
+}
+
+// loadConfig resolves the API key and server. The environment takes precedence over the configuration file,
+// which is read from path if set or else from the first default location that exists.
+func loadConfig(path string, getenv func(string) string) (config, error) {
+	var cfg config
+	if path != "" {
+		var err error
+		if cfg, err = parseConfig(path); err != nil {
+			return cfg, err
+		}
+	} else {
+		for _, p := range configPaths(getenv) {
+			if c, err := parseConfig(p); err == nil {
+				cfg = c
+				break
+			} else if !os.IsNotExist(err) {
+				return cfg, err
+			}
+		}
+	}
+	if key := getenv("DNSDB_API_KEY"); key != "" {
+		cfg.APIKey = key
+	}
+	if server := getenv("DNSDB_SERVER"); server != "" {
+		cfg.Server = server
+	}
+	return cfg, nil
+}
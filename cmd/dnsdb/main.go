@@ -0,0 +1,194 @@
+// Command dnsdb queries the Farsight DNSDB API.
+//
+// Usage:
+//
+//	dnsdb [flags] rrset name <owner> [rrtype [bailiwick]]
+//	dnsdb [flags] rdata name <name> [rrtype]
+//	dnsdb [flags] rdata ip <ip|cidr> [rrtype]
+//	dnsdb [flags] rdata raw <hex> [rrtype]
+//	dnsdb [flags] -f <batch file>
+//
+// The API key is read from the DNSDB_API_KEY environment variable or from a dnsdbq style configuration file,
+// ~/.dnsdb-query.conf or /etc/dnsdb-query.conf by default, containing APIKEY="...".
+package main
+
+// Imports
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bored-engineer/go-dnsdb"
+)
+
+// version is reported to the API as the client version
+const version = "0.1.0"
+
+// options are the parsed command line flags
+type options struct {
+	config    string
+	server    string
+	format    string
+	v2        bool
+	summarize bool
+	paginate  bool
+	batch     string
+	workers   int
+	lookup    dnsdb.LookupOptions
+	encoder   dnsdb.EncoderOptions
+}
+
+// timeFlag returns a flag.Value parsing absolute or relative times into dst
+func timeFlag(dst *time.Time, now time.Time) func(string) error {
+	return func(s string) (err error) {
+		*dst, err = dnsdb.ParseTime(s, now)
+		return err
+	}
+}
+
+// parseFlags parses the command line, returning the remaining arguments
+func parseFlags(args []string, stderr io.Writer) (*options, []string, error) {
+	opts := &options{}
+	now := time.Now()
+	fs := flag.NewFlagSet("dnsdb", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "Usage:\n")
+		fmt.Fprintf(stderr, "  dnsdb [flags] rrset name <owner> [rrtype [bailiwick]]\n")
+		fmt.Fprintf(stderr, "  dnsdb [flags] rdata name|ip|raw <value> [rrtype]\n")
+		fmt.Fprintf(stderr, "  dnsdb [flags] -f <batch file>\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	fs.StringVar(&opts.config, "config", "", "configuration file (default ~/.dnsdb-query.conf or /etc/dnsdb-query.conf)")
+	fs.StringVar(&opts.server, "server", "", "API server URL, overrides DNSDB_SERVER and the configuration file")
+	fs.StringVar(&opts.format, "format", "text", "output format: "+strings.Join(formatNames(), ", "))
+	fs.Func("time-format", "output time format: unix, rfc3339, iso or a Go layout (default depends on the format)", func(s string) error {
+		if layout, ok := timeFormats[s]; ok {
+			s = layout
+		}
+		opts.encoder.TimeFormat = s
+		return nil
+	})
+	fs.Func("trailing-dot", "trailing dot of owner names: keep, add or strip (default keep)", func(s string) error {
+		mode, ok := trailingDots[s]
+		if !ok {
+			return fmt.Errorf("unknown mode %q", s)
+		}
+		opts.encoder.TrailingDot = mode
+		return nil
+	})
+	fs.BoolVar(&opts.v2, "v2", false, "use API version 2")
+	fs.BoolVar(&opts.summarize, "summarize", false, "summarize the results instead of listing them")
+	fs.StringVar(&opts.batch, "f", "", "read queries from a dnsdbq batch file, - for stdin")
+	fs.IntVar(&opts.workers, "workers", 4, "concurrent queries in batch mode")
+	fs.BoolVar(&opts.paginate, "paginate", false, "fetch every result by paging with offset")
+	fs.Int64Var(&opts.lookup.Limit, "limit", 0, "maximum number of results")
+	fs.Int64Var(&opts.lookup.Offset, "offset", 0, "number of results to skip")
+	fs.Int64Var(&opts.lookup.MaxCount, "max-count", 0, "stop summarizing after this many records")
+	fs.Func("time-first-before", "only results first seen before this time", timeFlag(&opts.lookup.TimeFirstBefore, now))
+	fs.Func("time-first-after", "only results first seen after this time", timeFlag(&opts.lookup.TimeFirstAfter, now))
+	fs.Func("time-last-before", "only results last seen before this time", timeFlag(&opts.lookup.TimeLastBefore, now))
+	fs.Func("time-last-after", "only results last seen after this time", timeFlag(&opts.lookup.TimeLastAfter, now))
+	fs.Func("aggr", "set to false for unaggregated results", func(s string) error {
+		b, err := strconv.ParseBool(s)
+		opts.lookup.Aggr = dnsdb.Bool(b)
+		return err
+	})
+	fs.BoolVar(&opts.lookup.HumanTime, "humantime", false, "request times as strings")
+	if err := fs.Parse(args); err != nil {
+		return nil, nil, err
+	}
+	if _, ok := formats[opts.format]; !ok {
+		return nil, nil, fmt.Errorf("unknown format %q", opts.format)
+	}
+	if opts.summarize && opts.paginate {
+		return nil, nil, errors.New("-summarize cannot be combined with -paginate")
+	}
+	if opts.summarize && (opts.format == "csv" || opts.format == "tsv") {
+		return nil, nil, fmt.Errorf("-summarize cannot be combined with -format %s", opts.format)
+	}
+	opts.lookup.SWClient = "go-dnsdb"
+	opts.lookup.Version = version
+	return opts, fs.Args(), nil
+}
+
+// newClient returns a client configured from the options and environment
+func newClient(opts *options, getenv func(string) string) (*dnsdb.Client, error) {
+	cfg, err := loadConfig(opts.config, getenv)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.APIKey == "" {
+		return nil, errors.New("no API key, set DNSDB_API_KEY or APIKEY in the configuration file")
+	}
+	tp := &dnsdb.APIKeyTransport{APIKey: cfg.APIKey, Transport: &dnsdb.RetryTransport{}}
+	c := dnsdb.NewClient(tp.Client())
+	if opts.server != "" {
+		cfg.Server = opts.server
+	}
+	if cfg.Server != "" {
+		if !strings.HasSuffix(cfg.Server, "/") {
+			cfg.Server += "/"
+		}
+		if c.BaseURL, err = url.Parse(cfg.Server); err != nil {
+			return nil, err
+		}
+	}
+	if opts.v2 {
+		c.APIVersion = dnsdb.APIv2
+	}
+	return c, nil
+}
+
+// run executes the command line, it is separate from main for testing
+func run(ctx context.Context, args []string, getenv func(string) string, stdin io.Reader, stdout, stderr io.Writer) error {
+	opts, args, err := parseFlags(args, stderr)
+	if err != nil {
+		return err
+	}
+	var q *dnsdb.Query
+	if opts.batch != "" {
+		if len(args) > 0 || opts.summarize || opts.paginate {
+			return errors.New("-f cannot be combined with a query, -summarize or -paginate")
+		}
+	} else if q, err = parseQuery(args); err != nil {
+		return err
+	}
+	c, err := newClient(opts, getenv)
+	if err != nil {
+		return err
+	}
+	out := formats[opts.format](stdout, &opts.encoder)
+	if q == nil {
+		err = runBatch(ctx, c, opts, stdin, stdout, out, stderr)
+	} else {
+		err = runQuery(ctx, c, *q, opts, out, stderr)
+	}
+	if ferr := out.Flush(); err == nil {
+		err = ferr
+	}
+	return err
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if err := run(ctx, os.Args[1:], os.Getenv, os.Stdin, os.Stdout, os.Stderr); err != nil {
+		if msg := err.Error(); !errors.Is(err, flag.ErrHelp) {
+			// Errors from the library are already prefixed
+			if !strings.HasPrefix(msg, "dnsdb: ") {
+				msg = "dnsdb: " + msg
+			}
+			fmt.Fprintln(os.Stderr, msg)
+		}
+		os.Exit(1)
+	}
+}
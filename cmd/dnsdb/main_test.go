@@ -0,0 +1,188 @@
+package main
+
+import (
+	"github.com/stretchr/testify/assert"
+
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bored-engineer/go-dnsdb"
+	"github.com/bored-engineer/go-dnsdb/dnsdbtest"
+)
+
+// runTest runs the command line against srv and returns the output
+func runTest(srv *dnsdbtest.Server, args ...string) (string, error) {
+	return runStdin(srv, "", args...)
+}
+
+// runStdin runs the command line against srv with the provided stdin and returns the output
+func runStdin(srv *dnsdbtest.Server, stdin string, args ...string) (string, error) {
+	env := map[string]string{"DNSDB_API_KEY": srv.APIKey, "DNSDB_SERVER": srv.URL}
+	var stdout, stderr bytes.Buffer
+	err := run(context.Background(), args, func(k string) string { return env[k] }, strings.NewReader(stdin), &stdout, &stderr)
+	return stdout.String() + stderr.String(), err
+}
+
+func Test_run(t *testing.T) {
+	srv := dnsdbtest.NewServer()
+	srv.APIKey = "secret"
+	defer srv.Close()
+	srv.Add(
+		dnsdb.RRSet{
+			Count: dnsdb.Uint64(3), TimeFirst: dnsdb.NewTimestamp(1000), TimeLast: dnsdb.NewTimestamp(2000),
+			RRName: dnsdb.String("www.example.com."), RRType: dnsdb.String("A"), Bailiwick: dnsdb.String("example.com."),
+			RData: []string{"192.0.2.1", "192.0.2.2"},
+		},
+		dnsdb.RRSet{
+			Count: dnsdb.Uint64(4), TimeFirst: dnsdb.NewTimestamp(1000), TimeLast: dnsdb.NewTimestamp(2000),
+			RRName: dnsdb.String("example.com."), RRType: dnsdb.String("MX"), Bailiwick: dnsdb.String("example.com."),
+			RData: []string{"10 www.example.com."},
+		},
+	)
+
+	// Verify that rrset lookups print one line per rdata
+	out, err := runTest(srv, "rrset", "name", "www.example.com", "A")
+	assert.Nil(t, err)
+	assert.Equal(t, "www.example.com. A 192.0.2.1\nwww.example.com. A 192.0.2.2\n", out)
+
+	// Verify that rdata ip, network and name lookups work in either API version
+	out, err = runTest(srv, "-v2", "rdata", "ip", "192.0.2.2")
+	assert.Nil(t, err)
+	assert.Equal(t, "www.example.com. A 192.0.2.2\n", out)
+	out, err = runTest(srv, "-limit", "1", "rdata", "ip", "192.0.2.0,24")
+	assert.Nil(t, err)
+	assert.Equal(t, "www.example.com. A 192.0.2.1\n", out)
+	out, err = runTest(srv, "-v2", "-limit", "1", "rdata", "ip", "192.0.2.0,24")
+	assert.Nil(t, err)
+	assert.Equal(t, "www.example.com. A 192.0.2.1\ndnsdb: warning: results limited: Result limit reached\n", out)
+	out, err = runTest(srv, "rdata", "name", "www.example.com", "MX")
+	assert.Nil(t, err)
+	assert.Equal(t, "example.com. MX 10 www.example.com.\n", out)
+	out, err = runTest(srv, "-paginate", "rdata", "ip", "192.0.2.0/24", "A")
+	assert.Nil(t, err)
+	assert.Equal(t, "www.example.com. A 192.0.2.1\nwww.example.com. A 192.0.2.2\n", out)
+	out, err = runTest(srv, "-paginate", "rrset", "name", "*.example.com", "ANY", "example.com")
+	assert.Nil(t, err)
+	assert.Equal(t, "www.example.com. A 192.0.2.1\nwww.example.com. A 192.0.2.2\n", out)
+
+	// Verify that raw lookups, summaries and JSON output work
+	out, err = runTest(srv, "-format", "json", "rdata", "raw", "c0000201")
+	assert.Nil(t, err)
+	assert.Contains(t, out, `"rdata":"192.0.2.1"`)
+	out, err = runTest(srv, "-summarize", "rrset", "name", "*.example.com")
+	assert.Nil(t, err)
+	assert.Equal(t, "count 3, 1 results, first seen 1970-01-01T00:16:40Z, last seen 1970-01-01T00:33:20Z\n", out)
+	out, err = runTest(srv, "-summarize", "rrset", "name", "missing.example.com")
+	assert.Nil(t, err)
+	assert.Equal(t, "", out)
+
+	// Verify that bad arguments fail before any request is made
+	requests := srv.Requests()
+	_, err = runTest(srv, "rdata", "mx", "example.com")
+	assert.NotNil(t, err)
+	_, err = runTest(srv, "-format", "xml", "rrset", "name", "example.com")
+	assert.NotNil(t, err)
+	_, err = runTest(srv, "rdata", "ip", "nope")
+	assert.NotNil(t, err)
+	_, err = runTest(srv, "-summarize", "-paginate", "rrset", "name", "example.com")
+	assert.NotNil(t, err)
+	_, err = runTest(srv, "-summarize", "-format", "csv", "rrset", "name", "example.com")
+	assert.NotNil(t, err)
+	_, err = runTest(srv, "-summarize", "-format", "tsv", "rrset", "name", "example.com")
+	assert.NotNil(t, err)
+	_, err = runTest(srv, "rrset", "name", "example.com", "A", "com", "extra")
+	assert.NotNil(t, err)
+	_, err = runTest(srv, "rrset", "ip", "192.0.2.1")
+	assert.NotNil(t, err)
+	assert.Equal(t, requests, srv.Requests())
+}
+
+func Test_loadConfig(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, ".dnsdb-query.conf")
+	assert.Nil(t, os.WriteFile(path, []byte("# dnsdbq\nAPIKEY=\"from-file\"\nexport DNSDB_SERVER='https://example.com'\n"), 0600))
+
+	// Verify that the default location is read
+	env := map[string]string{"HOME": dir}
+	getenv := func(k string) string { return env[k] }
+	cfg, err := loadConfig("", getenv)
+	assert.Nil(t, err)
+	assert.Equal(t, config{APIKey: "from-file", Server: "https://example.com"}, cfg)
+
+	// Verify that the environment takes precedence
+	env["DNSDB_API_KEY"] = "from-env"
+	cfg, err = loadConfig(path, getenv)
+	assert.Nil(t, err)
+	assert.Equal(t, "from-env", cfg.APIKey)
+
+	// Verify that a missing explicit file fails
+	_, err = loadConfig(filepath.Join(dir, "missing"), getenv)
+	assert.NotNil(t, err)
+}
+
+func Test_run_formats(t *testing.T) {
+	srv := dnsdbtest.NewServer()
+	srv.APIKey = "secret"
+	defer srv.Close()
+	srv.Add(dnsdb.RRSet{
+		Count: dnsdb.Uint64(3), TimeFirst: dnsdb.NewTimestamp(1000), TimeLast: dnsdb.NewTimestamp(2000),
+		RRName: dnsdb.String("www.example.com."), RRType: dnsdb.String("A"), Bailiwick: dnsdb.String("example.com."),
+		RData: []string{"192.0.2.1"},
+	})
+
+	// Verify that the library encoders are selectable along with their options
+	out, err := runTest(srv, "-format", "dig", "-time-format", "iso", "rrset", "name", "www.example.com")
+	assert.Nil(t, err)
+	assert.Equal(t, ";; first seen: 1970-01-01 00:16:40\n;;  last seen: 1970-01-01 00:33:20\n;; count: 3; bailiwick: example.com.\nwww.example.com.  A  192.0.2.1\n\n", out)
+	out, err = runTest(srv, "-format", "tsv", "-trailing-dot", "strip", "rdata", "ip", "192.0.2.1")
+	assert.Nil(t, err)
+	assert.Equal(t, "time_first\ttime_last\tzone_time_first\tzone_time_last\tcount\tbailiwick\trrname\trrtype\trdata\n1000\t2000\t\t\t3\t\twww.example.com\tA\t192.0.2.1\n", out)
+	out, err = runTest(srv, "-format", "text", "-trailing-dot", "strip", "rrset", "name", "www.example.com")
+	assert.Nil(t, err)
+	assert.Equal(t, "www.example.com A 192.0.2.1\n", out)
+	_, err = runTest(srv, "-trailing-dot", "maybe", "rrset", "name", "www.example.com")
+	assert.NotNil(t, err)
+}
+
+func Test_run_batch(t *testing.T) {
+	srv := dnsdbtest.NewServer()
+	srv.APIKey = "secret"
+	defer srv.Close()
+	srv.Add(
+		dnsdb.RRSet{
+			RRName: dnsdb.String("www.example.com."), RRType: dnsdb.String("A"), RData: []string{"192.0.2.1", "192.0.2.2"},
+		},
+		dnsdb.RRSet{
+			RRName: dnsdb.String("mail.example.com."), RRType: dnsdb.String("A"), RData: []string{"192.0.2.3"},
+		},
+	)
+
+	// Verify that lines run in order with the flags as defaults for $options, each followed by a separator,
+	// and that failures are reported by line without stopping the other lines
+	out, err := runStdin(srv, "rrset/name/www.example.com/A\nrdata/bogus/x\n\n$options -l 2\nrdata/ip/192.0.2.0,24\nrdata/raw/zz\n", "-limit", "1", "-f", "-")
+	assert.EqualError(t, err, "2 of 4 queries failed")
+	assert.Equal(t, `www.example.com. A 192.0.2.1
+www.example.com. A 192.0.2.2
+--
+--
+www.example.com. A 192.0.2.1
+www.example.com. A 192.0.2.2
+--
+--
+line 2: rdata/bogus/x: dnsdb: invalid batch query "rdata/bogus/x"
+line 6: rdata/raw/zz: dnsdb: invalid raw rdata "zz": encoding/hex: invalid byte: U+007A 'z'
+`, out)
+
+	// Verify that a batch file can be read and is exclusive with a query
+	path := filepath.Join(t.TempDir(), "batch.txt")
+	assert.Nil(t, os.WriteFile(path, []byte("rdata/ip/192.0.2.3\n"), 0600))
+	out, err = runTest(srv, "-f", path)
+	assert.Nil(t, err)
+	assert.Equal(t, "mail.example.com. A 192.0.2.3\n--\n", out)
+	_, err = runTest(srv, "-f", path, "rrset", "name", "example.com")
+	assert.NotNil(t, err)
+}
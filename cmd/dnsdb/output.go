@@ -0,0 +1,106 @@
+package main
+
+// Imports
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/bored-engineer/go-dnsdb"
+)
+
+// output writes results in a specific format
+type output interface {
+	dnsdb.Encoder
+	Summary(dnsdb.Summary) error
+}
+
+// formats maps the -format names to their constructors
+var formats = map[string]func(io.Writer, *dnsdb.EncoderOptions) output{
+	"text": func(w io.Writer, opt *dnsdb.EncoderOptions) output {
+		return &textSummary{dnsdb.NewTextEncoder(w, opt), w}
+	},
+	"dig": func(w io.Writer, opt *dnsdb.EncoderOptions) output {
+		return &textSummary{dnsdb.NewPresentationEncoder(w, opt), w}
+	},
+	"csv": func(w io.Writer, opt *dnsdb.EncoderOptions) output {
+		return &textSummary{dnsdb.NewCSVEncoder(w, opt), w}
+	},
+	"tsv": func(w io.Writer, opt *dnsdb.EncoderOptions) output {
+		return &textSummary{dnsdb.NewTSVEncoder(w, opt), w}
+	},
+	"json": func(w io.Writer, opt *dnsdb.EncoderOptions) output {
+		return &jsonSummary{dnsdb.NewNDJSONEncoder(w, opt), w}
+	},
+}
+
+// formatNames returns the sorted names of the formats
+func formatNames() []string {
+	var names []string
+	for name := range formats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// trailingDots maps the -trailing-dot names to their modes
+var trailingDots = map[string]dnsdb.TrailingDot{
+	"keep":  dnsdb.TrailingDotKeep,
+	"add":   dnsdb.TrailingDotAdd,
+	"strip": dnsdb.TrailingDotStrip,
+}
+
+// timeFormats maps the -time-format shorthands to layouts, any other value is used as a layout
+var timeFormats = map[string]string{
+	"unix":    "",
+	"rfc3339": time.RFC3339,
+	"iso":     "2006-01-02 15:04:05",
+}
+
+// jsonSummary adds summaries to an NDJSON encoder
+type jsonSummary struct {
+	dnsdb.Encoder
+	w io.Writer
+}
+
+func (o *jsonSummary) Summary(s dnsdb.Summary) error {
+	return json.NewEncoder(o.w).Encode(s)
+}
+
+// textSummary adds summaries to an encoder as a single line of text
+type textSummary struct {
+	dnsdb.Encoder
+	w io.Writer
+}
+
+func (o *textSummary) Summary(s dnsdb.Summary) error {
+	_, err := io.WriteString(o.w, summaryLine(s))
+	return err
+}
+
+// stamp formats an optional timestamp
+func stamp(t *dnsdb.Timestamp) string {
+	if t == nil {
+		return "-"
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// summaryLine formats a summary as a line of text
+func summaryLine(s dnsdb.Summary) string {
+	var count, results uint64
+	if s.Count != nil {
+		count = *s.Count
+	}
+	if s.NumResults != nil {
+		results = *s.NumResults
+	}
+	first, last := s.TimeFirst, s.TimeLast
+	if first == nil {
+		first, last = s.ZoneTimeFirst, s.ZoneTimeLast
+	}
+	return fmt.Sprintf("count %d, %d results, first seen %s, last seen %s\n", count, results, stamp(first), stamp(last))
+}
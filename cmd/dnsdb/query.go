@@ -0,0 +1,73 @@
+package main
+
+// Imports
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+
+	"github.com/bored-engineer/go-dnsdb"
+)
+
+// parseQuery parses the positional arguments into a query with dnsdb.ParseQueryURL, so they are validated
+// exactly like an API URL
+func parseQuery(args []string) (*dnsdb.Query, error) {
+	if len(args) < 3 {
+		return nil, fmt.Errorf("expected <rrset|rdata> <name|ip|raw> <value>, see -help")
+	}
+	segments := make([]string, len(args))
+	for i, arg := range args {
+		segments[i] = url.PathEscape(arg)
+	}
+	q, err := dnsdb.ParseQueryURL("lookup/" + strings.Join(segments, "/"))
+	if err != nil {
+		return nil, err
+	}
+	return &q, nil
+}
+
+// warnCondition warns on stderr if an APIv2 stream ended without succeeding, such as when the results were limited
+func warnCondition(stderr io.Writer, resp *dnsdb.Response) {
+	if resp == nil || !resp.Condition.Terminal() || resp.Condition == dnsdb.ConditionSucceeded {
+		return
+	}
+	fmt.Fprintf(stderr, "dnsdb: warning: results %s: %s\n", resp.Condition, resp.Message)
+}
+
+// runQuery performs the query and writes the results, a stream which did not succeed is warned about on stderr
+func runQuery(ctx context.Context, c *dnsdb.Client, q dnsdb.Query, opts *options, out output, stderr io.Writer) error {
+	q.LookupOptions = opts.lookup
+	switch {
+	case opts.summarize:
+		q.Summarize = true
+		summary, resp, err := c.SummarizeQuery(ctx, q)
+		if err != nil {
+			return err
+		}
+		warnCondition(stderr, resp)
+		if summary == nil {
+			return nil // Nothing to summarize
+		}
+		return out.Summary(*summary)
+	case opts.paginate:
+		rrsets, rdata, err := q.Paginate(ctx, c)
+		if err != nil {
+			return err
+		}
+		if rrsets != nil {
+			return dnsdb.EncodeRRSets(out, rrsets)
+		}
+		return dnsdb.EncodeRData(out, rdata)
+	}
+	rrsets, rdata, resp, err := q.Stream(ctx, c)
+	if err != nil {
+		return err
+	}
+	defer warnCondition(stderr, resp)
+	if rrsets != nil {
+		return dnsdb.EncodeRRSets(out, rrsets)
+	}
+	return dnsdb.EncodeRData(out, rdata)
+}
@@ -2,15 +2,26 @@ package dnsdb
 
 // Imports
 import (
-	"fmt"
+	"context"
 	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
+	"sync"
 )
 
 const (
 	baseURL   = "https://api.dnsdb.info/"
 	userAgent = "go-dnsdb"
+	v2Prefix  = "dnsdb/v2/"
+)
+
+// APIVersion selects which version of the DNSDB API a Client speaks
+type APIVersion int
+
+const (
+	APIv1 APIVersion = iota // The original API which returns bare JSON lines under the base URL
+	APIv2                   // The API under /dnsdb/v2/ which wraps every line in the Streaming Application Format
 )
 
 // A Client manages communication with the DNSDB API.
@@ -23,11 +34,30 @@ type Client struct {
 	// User agent used when communicating with the DNSDB API.
 	UserAgent string
 
+	// Version of the DNSDB API to use for lookups. Defaults to APIv1.
+	APIVersion APIVersion
+
+	// Cache stores the results of queries on local disk, if nil results are not cached.
+	Cache *DiskCache
+
+	// Limiter throttles requests made by the client, if nil requests are not throttled.
+	// The rate_limit and ping methods do not count against the quota so they are never throttled.
+	Limiter *Limiter
+
+	// NoResultsAsError makes lookups without any results fail with ErrNoResults instead of returning an empty result.
+	NoResultsAsError bool
+
+	rateMu sync.Mutex // Protects rate
+	rate   Rate       // Latest rate seen in any response
+
 	common service // Reuse a single struct instead of allocating one for each service on the heap.
 
 	// Service are used for communication with the different parts of the DNSDB API.
-	RRSet *RRSetService
-	RData *RDataService
+	RRSet   *RRSetService
+	RData   *RDataService
+	Flex    *FlexService
+	Account *AccountService
+	Batch   *BatchService
 }
 
 type service struct {
@@ -45,18 +75,22 @@ func NewClient(httpClient *http.Client) *Client {
 	c.common.client = c
 	c.RRSet = (*RRSetService)(&c.common)
 	c.RData = (*RDataService)(&c.common)
+	c.Flex = (*FlexService)(&c.common)
+	c.Account = (*AccountService)(&c.common)
+	c.Batch = (*BatchService)(&c.common)
 
 	return c
 }
 
-// NewRequest creates an http.Request object or returns and error. A relative URL can be provided in urlStr
-func (c *Client) NewRequest(method, urlStr string, body interface{}) (*http.Request, error) {
+// NewRequest creates an http.Request object or returns and error. A relative URL can be provided in urlStr.
+// The provided context is attached to the request and governs its entire lifetime, including reading the body.
+func (c *Client) NewRequest(ctx context.Context, method, urlStr string, body interface{}) (*http.Request, error) {
 	rel, err := url.Parse(urlStr)
 	if err != nil {
 		return nil, err
 	}
 
-	req, err := http.NewRequest(method, c.BaseURL.ResolveReference(rel).String(), nil)
+	req, err := http.NewRequestWithContext(ctx, method, c.BaseURL.ResolveReference(rel).String(), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -66,11 +100,19 @@ func (c *Client) NewRequest(method, urlStr string, body interface{}) (*http.Requ
 	return req, nil
 }
 
+// accept returns the media type of lookup results for the APIVersion
+func (v APIVersion) accept() string {
+	if v == APIv2 {
+		return "application/x-ndjson"
+	}
+	return "application/json"
+}
+
 // Rate represents the current rate limit
 type Rate struct {
-	Limit     int       `json: "reset"`
-	Remaining int       `json: "limit"`
-	Reset     Timestamp `json: "remaining"`
+	Limit     int       `json:"limit"`
+	Remaining int       `json:"remaining"`
+	Reset     Timestamp `json:"reset"`
 }
 
 // Extracts a rate from the response
@@ -96,13 +138,61 @@ type Response struct {
 	*http.Response
 
 	Rate
+
+	// Condition and Message are the most recent SAF condition read from an APIv2 stream.
+	// They are updated as the results are read, once the stream is exhausted Condition is terminal.
+	Condition Condition
+	Message   string
+
+	// Cached is set when the results were read from the Cache of the client and no quota was spent.
+	Cached bool
+}
+
+// Rate returns the latest rate seen in any response, it is safe to call from multiple goroutines
+func (c *Client) Rate() Rate {
+	c.rateMu.Lock()
+	defer c.rateMu.Unlock()
+	return c.rate
+}
+
+// setRate records a rate unless it is older than the one already seen.
+// Concurrent responses can finish in any order, so only a later reset or fewer remaining for the same reset replace it.
+func (c *Client) setRate(rate Rate) {
+	c.rateMu.Lock()
+	defer c.rateMu.Unlock()
+	switch {
+	case c.rate == Rate{}:
+	case rate.Reset.After(c.rate.Reset.Time):
+	case rate.Reset.Equal(c.rate.Reset.Time) && rate.Remaining < c.rate.Remaining:
+	default:
+		return
+	}
+	c.rate = rate
+}
+
+// unmetered reports if a request does not count against the quota
+func unmetered(req *http.Request) bool {
+	return strings.HasSuffix(req.URL.Path, "/rate_limit") || strings.HasSuffix(req.URL.Path, "/ping")
 }
 
 // Do sends the provided http.Request and returns the response from DNSDB.
+// The request is cancelled when the context of req is done.
+// If the client has a Limiter the request first waits for it, unless it does not count against the quota.
 func (c *Client) Do(req *http.Request) (*Response, error) {
+	// Wait for the limiter
+	if c.Limiter != nil && !unmetered(req) {
+		if err := c.Limiter.Wait(req.Context(), c.Rate()); err != nil {
+			return nil, err
+		}
+	}
+
 	// Actually do the request
 	resp, err := c.client.Do(req)
 	if err != nil {
+		// If the context was cancelled, its error is more useful than the transport's
+		if ctxErr := req.Context().Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
 		return nil, err
 	}
 
@@ -111,10 +201,13 @@ func (c *Client) Do(req *http.Request) (*Response, error) {
 		Response: resp,
 		Rate:     extractRateFromResponse(resp),
 	}
+	if resp.Header.Get("X-RateLimit-Limit") != "" {
+		c.setRate(response.Rate)
+	}
 
-	// If API returned an error, return the response and err back to user to inspect
+	// If API returned an error, return the response and an *ErrorResponse back to user to inspect
 	if resp.StatusCode != 200 {
-		return response, fmt.Errorf("A unexpected status code (%d) was returned", resp.StatusCode)
+		return response, newErrorResponse(response)
 	}
 
 	// Return success
@@ -130,3 +223,8 @@ func String(string string) *string {
 func Uint64(uint64 uint64) *uint64 {
 	return &uint64
 }
+
+// Bool allocates a new bool value to store and returns a pointer to it.
+func Bool(bool bool) *bool {
+	return &bool
+}
@@ -0,0 +1,589 @@
+// Package dnsdbtest provides a fake DNSDB API server backed by an in-memory record store, for testing code which uses dnsdb.
+//
+// The server implements the rrset and rdata lookup and summarize methods of both API versions, including wildcards,
+// rrtype and bailiwick filtering, time fencing, limit and offset, the rate_limit and ping endpoints and rate limit headers.
+package dnsdbtest
+
+// Imports
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bored-engineer/go-dnsdb"
+)
+
+// Server is a fake DNSDB API server
+type Server struct {
+	*httptest.Server
+
+	// APIKey is required in the X-API-Key header of every request if set
+	APIKey string
+
+	mu       sync.Mutex
+	records  []dnsdb.RRSet
+	quota    dnsdb.Quota
+	requests int
+}
+
+// NewServer starts and returns a new Server with an unlimited quota, the caller should call Close when finished
+func NewServer() *Server {
+	s := &Server{
+		quota: dnsdb.Quota{
+			Limit:       -1,
+			Remaining:   -1,
+			ResultsMax:  10000,
+			OffsetMax:   -1,
+			BurstSize:   -1,
+			BurstWindow: -1,
+		},
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Add stores records in the server, they are returned by lookups in the order they were added
+func (s *Server) Add(records ...dnsdb.RRSet) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records = append(s.records, records...)
+}
+
+// SetQuota replaces the quota of the server. A positive Limit is enforced, Remaining counts down with every lookup.
+func (s *Server) SetQuota(q dnsdb.Quota) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.quota = q
+}
+
+// Quota returns the current quota of the server
+func (s *Server) Quota() dnsdb.Quota {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.quota
+}
+
+// Requests returns how many lookup and summarize requests the server has served
+func (s *Server) Requests() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests
+}
+
+// Client returns a dnsdb.Client which talks to the server
+func (s *Server) Client() *dnsdb.Client {
+	tp := &dnsdb.APIKeyTransport{APIKey: s.APIKey}
+	c := dnsdb.NewClient(tp.Client())
+	c.BaseURL, _ = url.Parse(s.URL + "/")
+	return c
+}
+
+// rateHeaders writes the X-RateLimit-* headers for the quota
+func rateHeaders(w http.ResponseWriter, q dnsdb.Quota) {
+	limit, remaining, reset := "unlimited", "n/a", "n/a"
+	if q.Limit >= 0 {
+		limit = strconv.Itoa(q.Limit)
+	}
+	if q.Remaining >= 0 {
+		remaining = strconv.Itoa(q.Remaining)
+	}
+	if q.Reset != nil {
+		reset = strconv.FormatInt(q.Reset.Unix(), 10)
+	}
+	w.Header().Set("X-RateLimit-Limit", limit)
+	w.Header().Set("X-RateLimit-Remaining", remaining)
+	w.Header().Set("X-RateLimit-Reset", reset)
+}
+
+// quotaJSON renders the quota as the rate_limit endpoint does
+func quotaJSON(q dnsdb.Quota) map[string]interface{} {
+	num := func(i int, na string) interface{} {
+		if i < 0 {
+			return na
+		}
+		return i
+	}
+	rate := map[string]interface{}{
+		"limit":       num(q.Limit, "unlimited"),
+		"remaining":   num(q.Remaining, "n/a"),
+		"reset":       "n/a",
+		"results_max": num(q.ResultsMax, "n/a"),
+		"offset_max":  num(q.OffsetMax, "n/a"),
+	}
+	if q.Reset != nil {
+		rate["reset"] = q.Reset.Unix()
+	}
+	if q.Expires != nil {
+		rate["expires"] = q.Expires.Unix()
+	}
+	if q.BurstSize >= 0 {
+		rate["burst_size"] = q.BurstSize
+		rate["burst_window"] = q.BurstWindow
+	}
+	return map[string]interface{}{"rate": rate}
+}
+
+// serveHTTP routes a request
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if s.APIKey != "" && r.Header.Get("X-API-Key") != s.APIKey {
+		http.Error(w, "Error: API key not authorized", http.StatusForbidden)
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.EscapedPath(), "/")
+	v2 := strings.HasPrefix(path, "dnsdb/v2/")
+	path = strings.TrimPrefix(path, "dnsdb/v2/")
+	segments := strings.Split(path, "/")
+	for i, seg := range segments {
+		var err error
+		if segments[i], err = url.PathUnescape(seg); err != nil {
+			http.NotFound(w, r)
+			return
+		}
+	}
+
+	switch {
+	case (!v2 && path == "lookup/rate_limit") || (v2 && path == "rate_limit"):
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(quotaJSON(s.Quota()))
+	case v2 && path == "ping":
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"ping":"ok"}`+"\n")
+	case len(segments) >= 4 && (segments[0] == "lookup" || segments[0] == "summarize"):
+		s.serveQuery(w, r, v2, segments[0] == "summarize", segments[1:])
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// query is a parsed lookup or summarize request
+type query struct {
+	rrset     bool   // rrset as opposed to rdata
+	kind      string // name, ip or raw
+	value     string
+	rrtype    string
+	bailiwick string
+
+	timeFirstBefore, timeFirstAfter, timeLastBefore, timeLastAfter time.Time
+
+	limit, offset, maxCount int
+	humanTime               bool
+}
+
+// parseQuery parses the path segments after lookup or summarize and the query string
+func parseQuery(segments []string, values url.Values, now time.Time) (*query, error) {
+	q := &query{rrset: segments[0] == "rrset", kind: segments[1], value: segments[2]}
+	switch {
+	case q.rrset && q.kind == "name" && len(segments) <= 5:
+		if len(segments) == 5 {
+			q.bailiwick = segments[4]
+		}
+	case !q.rrset && segments[0] == "rdata" && (q.kind == "name" || q.kind == "ip" || q.kind == "raw") && len(segments) <= 4:
+	default:
+		return nil, fmt.Errorf("unknown path")
+	}
+	if len(segments) >= 4 {
+		q.rrtype = strings.ToUpper(segments[3])
+		if err := dnsdb.RRType(q.rrtype).Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, t := range []struct {
+		dst  *time.Time
+		name string
+	}{
+		{&q.timeFirstBefore, "time_first_before"},
+		{&q.timeFirstAfter, "time_first_after"},
+		{&q.timeLastBefore, "time_last_before"},
+		{&q.timeLastAfter, "time_last_after"},
+	} {
+		if v := values.Get(t.name); v != "" {
+			i, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				return nil, err
+			}
+			// Negative times are relative to now
+			if i < 0 {
+				*t.dst = now.Add(time.Duration(i) * time.Second)
+			} else {
+				*t.dst = time.Unix(i, 0)
+			}
+		}
+	}
+	for _, n := range []struct {
+		dst  *int
+		name string
+	}{
+		{&q.limit, "limit"},
+		{&q.offset, "offset"},
+		{&q.maxCount, "max_count"},
+	} {
+		if v := values.Get(n.name); v != "" {
+			i, err := strconv.Atoi(v)
+			if err != nil || i < 0 {
+				return nil, fmt.Errorf("invalid %s", n.name)
+			}
+			*n.dst = i
+		}
+	}
+	q.humanTime = values.Get("humantime") == "true" || values.Get("humantime") == "1"
+	return q, nil
+}
+
+// serveQuery serves a lookup or summarize request
+func (s *Server) serveQuery(w http.ResponseWriter, r *http.Request, v2, summarize bool, segments []string) {
+	q, err := parseQuery(segments, r.URL.Query(), time.Now())
+	if err != nil {
+		http.Error(w, "Error: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Spend the quota
+	s.mu.Lock()
+	s.requests++
+	quota := s.quota
+	if quota.Limit > 0 && quota.Remaining == 0 {
+		s.mu.Unlock()
+		rateHeaders(w, quota)
+		http.Error(w, "Error: Rate limit exceeded", http.StatusTooManyRequests)
+		return
+	}
+	if quota.Remaining > 0 {
+		s.quota.Remaining--
+		quota = s.quota
+	}
+	records := append([]dnsdb.RRSet(nil), s.records...)
+	s.mu.Unlock()
+	rateHeaders(w, quota)
+
+	results, err := q.match(records)
+	if err != nil {
+		http.Error(w, "Error: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Apply the offset and limit, the limit defaults to and is capped by results_max
+	if q.offset > 0 {
+		results = results[min(q.offset, len(results)):]
+	}
+	limit := q.limit
+	if quota.ResultsMax > 0 && (limit == 0 || limit > quota.ResultsMax) {
+		limit = quota.ResultsMax
+	}
+	limited := false
+	if !summarize && limit > 0 && len(results) > limit {
+		results, limited = results[:limit], true
+	}
+
+	var objs []interface{}
+	if summarize {
+		objs = append(objs, q.summarize(results))
+	} else {
+		for _, res := range results {
+			objs = append(objs, q.render(res))
+		}
+	}
+
+	if !v2 {
+		if len(results) == 0 {
+			http.Error(w, "Error: no results found for query.", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		for _, obj := range objs {
+			enc.Encode(obj)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	enc := json.NewEncoder(w)
+	enc.Encode(map[string]string{"cond": "begin"})
+	for _, obj := range objs {
+		enc.Encode(map[string]interface{}{"obj": obj})
+	}
+	if limited {
+		enc.Encode(map[string]string{"cond": "limited", "msg": "Result limit reached"})
+	} else {
+		enc.Encode(map[string]string{"cond": "succeeded"})
+	}
+}
+
+// result is a matched record, for rdata queries only the matching rdata is kept
+type result struct {
+	dnsdb.RRSet
+}
+
+// match returns the records matching the query
+func (q *query) match(records []dnsdb.RRSet) ([]result, error) {
+	var results []result
+	var nameMatch func(string) bool
+	if q.kind == "name" {
+		var err error
+		if nameMatch, err = nameMatcher(q.value); err != nil {
+			return nil, err
+		}
+	}
+	var rdataMatch func(dnsdb.RRType, string) bool
+	if !q.rrset {
+		var err error
+		if rdataMatch, err = q.rdataMatcher(nameMatch); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, rec := range records {
+		if rec.RRName == nil || rec.RRType == nil || !typeMatches(q.rrtype, *rec.RRType) || !q.fenceMatches(rec) {
+			continue
+		}
+		if q.rrset {
+			if !nameMatch(*rec.RRName) {
+				continue
+			}
+			if q.bailiwick != "" && (rec.Bailiwick == nil || canonical(*rec.Bailiwick) != canonical(q.bailiwick)) {
+				continue
+			}
+			results = append(results, result{rec})
+			continue
+		}
+		for _, rdata := range rec.RData {
+			if rdataMatch(rec.Type(), rdata) {
+				single := rec
+				single.RData = []string{rdata}
+				results = append(results, result{single})
+			}
+		}
+	}
+	return results, nil
+}
+
+// typeMatches reports if a record type matches the requested type, including the ANY pseudo-types
+func typeMatches(want, have string) bool {
+	have = strings.ToUpper(have)
+	switch want {
+	case "", string(dnsdb.RRTypeANY):
+		return !isDNSSEC(have)
+	case string(dnsdb.RRTypeANYDNSSEC):
+		return isDNSSEC(have)
+	}
+	return want == have
+}
+
+// isDNSSEC reports if the type is one of the DNSSEC types
+func isDNSSEC(t string) bool {
+	switch dnsdb.RRType(t) {
+	case dnsdb.RRTypeDS, dnsdb.RRTypeRRSIG, dnsdb.RRTypeNSEC, dnsdb.RRTypeDNSKEY, dnsdb.RRTypeNSEC3,
+		dnsdb.RRTypeNSEC3PARAM, dnsdb.RRTypeCDS, dnsdb.RRTypeCDNSKEY, dnsdb.RRTypeDLV:
+		return true
+	}
+	return false
+}
+
+// canonical lowercases a name and ensures it has a trailing dot
+func canonical(name string) string {
+	name = strings.ToLower(name)
+	if !strings.HasSuffix(name, ".") {
+		name += "."
+	}
+	return name
+}
+
+// nameMatcher returns a function matching names against a possibly wildcarded pattern.
+// "*.example.com" matches any name below example.com, "www.example.*" matches www.example under any TLD.
+func nameMatcher(pattern string) (func(string) bool, error) {
+	pattern = strings.ToLower(pattern)
+	switch {
+	case strings.HasPrefix(pattern, "*."):
+		suffix := "." + canonical(pattern[2:])
+		return func(name string) bool {
+			return strings.HasSuffix(canonical(name), suffix)
+		}, nil
+	case strings.HasSuffix(pattern, ".*") || strings.HasSuffix(pattern, ".*."):
+		prefix := strings.TrimSuffix(strings.TrimSuffix(pattern, "."), "*")
+		return func(name string) bool {
+			name = canonical(name)
+			return strings.HasPrefix(name, prefix) && len(name) > len(prefix)+1
+		}, nil
+	case strings.Contains(pattern, "*"):
+		return nil, fmt.Errorf("unsupported wildcard")
+	}
+	pattern = canonical(pattern)
+	return func(name string) bool {
+		return canonical(name) == pattern
+	}, nil
+}
+
+// rdataNames returns the domain names within parsed rdata
+func rdataNames(v dnsdb.RDataValue) []string {
+	switch v := v.(type) {
+	case dnsdb.RDataTarget:
+		return []string{v.Target}
+	case dnsdb.RDataMX:
+		return []string{v.Exchange}
+	case dnsdb.RDataSRV:
+		return []string{v.Target}
+	case dnsdb.RDataSOA:
+		return []string{v.MName, v.RName}
+	case dnsdb.RDataNAPTR:
+		return []string{v.Replacement}
+	case dnsdb.RDataNSEC:
+		return []string{v.NextDomain}
+	}
+	return nil
+}
+
+// rdataMatcher returns a function matching rdata against the query
+func (q *query) rdataMatcher(nameMatch func(string) bool) (func(dnsdb.RRType, string) bool, error) {
+	switch q.kind {
+	case "name":
+		return func(t dnsdb.RRType, rdata string) bool {
+			v, err := dnsdb.ParseRData(t, rdata)
+			if err != nil {
+				return false
+			}
+			for _, name := range rdataNames(v) {
+				if nameMatch(name) {
+					return true
+				}
+			}
+			return false
+		}, nil
+	case "ip":
+		prefix, err := parsePrefix(q.value)
+		if err != nil {
+			return nil, err
+		}
+		return func(t dnsdb.RRType, rdata string) bool {
+			v, err := dnsdb.ParseRData(t, rdata)
+			if err != nil {
+				return false
+			}
+			switch v := v.(type) {
+			case dnsdb.RDataA:
+				return prefix.Contains(v.Addr)
+			case dnsdb.RDataAAAA:
+				return prefix.Contains(v.Addr)
+			}
+			return false
+		}, nil
+	case "raw":
+		raw, err := hex.DecodeString(q.value)
+		if err != nil {
+			return nil, err
+		}
+		return func(t dnsdb.RRType, rdata string) bool {
+			wire, err := rdataWire(t, rdata)
+			return err == nil && string(wire) == string(raw)
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown lookup")
+}
+
+// fenceMatches applies the time fencing parameters to the lifetime of a record
+func (q *query) fenceMatches(rec dnsdb.RRSet) bool {
+	first, last := rec.TimeFirst, rec.TimeLast
+	if first == nil || last == nil {
+		first, last = rec.ZoneTimeFirst, rec.ZoneTimeLast
+	}
+	if first == nil || last == nil {
+		return true
+	}
+	return (q.timeFirstBefore.IsZero() || !first.After(q.timeFirstBefore)) &&
+		(q.timeFirstAfter.IsZero() || !first.Before(q.timeFirstAfter)) &&
+		(q.timeLastBefore.IsZero() || !last.After(q.timeLastBefore)) &&
+		(q.timeLastAfter.IsZero() || !last.Before(q.timeLastAfter))
+}
+
+// formatTime renders a time in the format requested
+func (q *query) formatTime(t *dnsdb.Timestamp) interface{} {
+	if t == nil {
+		return nil
+	}
+	if q.humanTime {
+		return t.UTC().Format(time.RFC3339)
+	}
+	return t.Unix()
+}
+
+// render returns the JSON object of a result
+func (q *query) render(res result) map[string]interface{} {
+	obj := map[string]interface{}{
+		"rrname": *res.RRName,
+		"rrtype": *res.RRType,
+	}
+	if res.Count != nil {
+		obj["count"] = *res.Count
+	}
+	for name, t := range map[string]*dnsdb.Timestamp{
+		"time_first":      res.TimeFirst,
+		"time_last":       res.TimeLast,
+		"zone_time_first": res.ZoneTimeFirst,
+		"zone_time_last":  res.ZoneTimeLast,
+	} {
+		if t != nil {
+			obj[name] = q.formatTime(t)
+		}
+	}
+	if q.rrset {
+		if res.Bailiwick != nil {
+			obj["bailiwick"] = *res.Bailiwick
+		}
+		obj["rdata"] = res.RData
+	} else {
+		obj["rdata"] = res.RData[0]
+	}
+	return obj
+}
+
+// summarize returns the JSON object summarizing the results
+func (q *query) summarize(results []result) map[string]interface{} {
+	var count uint64
+	var first, last, zoneFirst, zoneLast *dnsdb.Timestamp
+	earliest := func(a, b *dnsdb.Timestamp) *dnsdb.Timestamp {
+		if a == nil || (b != nil && b.Before(a.Time)) {
+			return b
+		}
+		return a
+	}
+	latest := func(a, b *dnsdb.Timestamp) *dnsdb.Timestamp {
+		if a == nil || (b != nil && b.After(a.Time)) {
+			return b
+		}
+		return a
+	}
+	for _, res := range results {
+		if q.maxCount > 0 && count >= uint64(q.maxCount) {
+			break
+		}
+		if res.Count != nil {
+			count += *res.Count
+		}
+		first, last = earliest(first, res.TimeFirst), latest(last, res.TimeLast)
+		zoneFirst, zoneLast = earliest(zoneFirst, res.ZoneTimeFirst), latest(zoneLast, res.ZoneTimeLast)
+	}
+	obj := map[string]interface{}{
+		"count":       count,
+		"num_results": len(results),
+	}
+	for name, t := range map[string]*dnsdb.Timestamp{
+		"time_first":      first,
+		"time_last":       last,
+		"zone_time_first": zoneFirst,
+		"zone_time_last":  zoneLast,
+	} {
+		if t != nil {
+			obj[name] = q.formatTime(t)
+		}
+	}
+	return obj
+}
@@ -0,0 +1,223 @@
+package dnsdbtest
+
+import (
+	"github.com/stretchr/testify/assert"
+
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/bored-engineer/go-dnsdb"
+)
+
+// testServer returns a server populated with a few records
+func testServer() *Server {
+	s := NewServer()
+	s.Add(
+		dnsdb.RRSet{
+			Count: dnsdb.Uint64(10), TimeFirst: dnsdb.NewTimestamp(1000), TimeLast: dnsdb.NewTimestamp(2000),
+			RRName: dnsdb.String("www.example.com."), RRType: dnsdb.String("A"), Bailiwick: dnsdb.String("example.com."),
+			RData: []string{"192.0.2.1", "192.0.2.2"},
+		},
+		dnsdb.RRSet{
+			Count: dnsdb.Uint64(5), TimeFirst: dnsdb.NewTimestamp(3000), TimeLast: dnsdb.NewTimestamp(4000),
+			RRName: dnsdb.String("mail.example.com."), RRType: dnsdb.String("A"), Bailiwick: dnsdb.String("com."),
+			RData: []string{"198.51.100.1"},
+		},
+		dnsdb.RRSet{
+			Count: dnsdb.Uint64(7), TimeFirst: dnsdb.NewTimestamp(1500), TimeLast: dnsdb.NewTimestamp(3500),
+			RRName: dnsdb.String("example.com."), RRType: dnsdb.String("MX"), Bailiwick: dnsdb.String("example.com."),
+			RData: []string{"10 mail.example.com."},
+		},
+		dnsdb.RRSet{
+			Count: dnsdb.Uint64(2), TimeFirst: dnsdb.NewTimestamp(1000), TimeLast: dnsdb.NewTimestamp(2000),
+			RRName: dnsdb.String("www.example.net."), RRType: dnsdb.String("A"), Bailiwick: dnsdb.String("example.net."),
+			RData: []string{"192.0.2.3"},
+		},
+	)
+	return s
+}
+
+// rrnames returns the owner names of the results
+func rrnames(records []dnsdb.RRSet) (names []string) {
+	for _, r := range records {
+		names = append(names, *r.RRName)
+	}
+	return names
+}
+
+func Test_Server_RRSet(t *testing.T) {
+	s := testServer()
+	defer s.Close()
+	c := s.Client()
+	ctx := context.Background()
+
+	// Verify that an exact name lookup matches any type
+	results, resp, err := c.RRSet.LookupName(ctx, "Example.com", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"example.com."}, rrnames(results))
+	assert.Equal(t, -1, resp.Rate.Limit)
+
+	// Verify that left and right wildcards match
+	results, _, err = c.RRSet.LookupName(ctx, "*.example.com", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"www.example.com.", "mail.example.com."}, rrnames(results))
+	results, _, err = c.RRSet.LookupName(ctx, "www.example.*", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"www.example.com.", "www.example.net."}, rrnames(results))
+
+	// Verify that rrtype and bailiwick filter the results
+	results, _, err = c.RRSet.LookupName(ctx, "*.example.com", &dnsdb.RRSetLookupNameOptions{RRType: "A", Bailiwick: "com"})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"mail.example.com."}, rrnames(results))
+
+	// Verify that time fencing, limit and offset are applied
+	results, _, err = c.RRSet.LookupName(ctx, "*.example.com", &dnsdb.RRSetLookupNameOptions{
+		LookupOptions: dnsdb.LookupOptions{TimeLastAfter: time.Unix(2500, 0)},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"mail.example.com."}, rrnames(results))
+	results, _, err = c.RRSet.LookupName(ctx, "*.example.com", &dnsdb.RRSetLookupNameOptions{
+		LookupOptions: dnsdb.LookupOptions{Limit: 1, Offset: 1},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"mail.example.com."}, rrnames(results))
+
+	// Verify that no results is an empty result and a summary totals the matches
+	results, _, err = c.RRSet.LookupName(ctx, "missing.example.com", nil)
+	assert.Nil(t, err)
+	assert.Empty(t, results)
+	summary, _, err := c.RRSet.SummarizeName(ctx, "*.example.com", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(15), *summary.Count)
+	assert.Equal(t, uint64(2), *summary.NumResults)
+	assert.Equal(t, int64(1000), summary.TimeFirst.Unix())
+	assert.Equal(t, int64(4000), summary.TimeLast.Unix())
+
+	assert.Equal(t, 8, s.Requests())
+}
+
+func Test_Server_RData(t *testing.T) {
+	s := testServer()
+	defer s.Close()
+	c := s.Client()
+	ctx := context.Background()
+
+	// Verify that an rdata name lookup finds the MX target
+	results, _, err := c.RData.LookupName(ctx, "mail.example.com", nil)
+	assert.Nil(t, err)
+	assert.Len(t, results, 1)
+	assert.Equal(t, "10 mail.example.com.", *results[0].RData)
+
+	// Verify that an IP lookup returns only the matching rdata
+	results, _, err = c.RData.LookupIP(ctx, net.ParseIP("192.0.2.2"), nil)
+	assert.Nil(t, err)
+	assert.Len(t, results, 1)
+	assert.Equal(t, "www.example.com.", *results[0].RRName)
+	assert.Equal(t, "192.0.2.2", *results[0].RData)
+
+	// Verify that a CIDR lookup returns every address in the network
+	_, ipnet, _ := net.ParseCIDR("192.0.2.0/24")
+	results, _, err = c.RData.LookupIPNet(ctx, *ipnet, nil)
+	assert.Nil(t, err)
+	assert.Len(t, results, 3)
+
+	// Verify that raw lookups match the wire form
+	results, _, err = c.RData.LookupRaw(ctx, []byte{198, 51, 100, 1}, nil)
+	assert.Nil(t, err)
+	assert.Len(t, results, 1)
+	assert.Equal(t, "mail.example.com.", *results[0].RRName)
+	results, _, err = c.RData.LookupRaw(ctx, []byte("\x00\x0a\x04mail\x07example\x03com\x00"), nil)
+	assert.Nil(t, err)
+	assert.Len(t, results, 1)
+	assert.Equal(t, "MX", *results[0].RRType)
+}
+
+func Test_Server_APIv2(t *testing.T) {
+	s := testServer()
+	defer s.Close()
+	c := s.Client()
+	c.APIVersion = dnsdb.APIv2
+	ctx := context.Background()
+
+	// Verify that results are framed and the condition is reported
+	results, resp, err := c.RRSet.LookupName(ctx, "*.example.com", nil)
+	assert.Nil(t, err)
+	assert.Len(t, results, 2)
+	assert.Equal(t, dnsdb.ConditionSucceeded, resp.Condition)
+
+	// Verify that a limit is reported as limited
+	results, resp, err = c.RRSet.LookupName(ctx, "*.example.com", &dnsdb.RRSetLookupNameOptions{
+		LookupOptions: dnsdb.LookupOptions{Limit: 1},
+	})
+	assert.Nil(t, err)
+	assert.Len(t, results, 1)
+	assert.Equal(t, dnsdb.ConditionLimited, resp.Condition)
+
+	// Verify that ping works
+	_, err = c.Account.Ping(ctx)
+	assert.Nil(t, err)
+}
+
+func Test_Server_Quota(t *testing.T) {
+	s := testServer()
+	s.APIKey = "secret"
+	defer s.Close()
+	c := s.Client()
+	ctx := context.Background()
+	reset := dnsdb.NewTimestamp(time.Now().Add(time.Hour).Unix())
+	s.SetQuota(dnsdb.Quota{Limit: 2, Remaining: 1, Reset: reset, ResultsMax: 1, OffsetMax: 10, BurstSize: -1, BurstWindow: -1})
+
+	// Verify that the rate_limit endpoint reports the quota
+	quota, _, err := c.Account.RateLimit(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, quota.Limit)
+	assert.Equal(t, 1, quota.Remaining)
+	assert.Equal(t, 1, quota.ResultsMax)
+
+	// Verify that lookups spend the quota and are capped by results_max
+	results, resp, err := c.RRSet.LookupName(ctx, "*.example.com", nil)
+	assert.Nil(t, err)
+	assert.Len(t, results, 1)
+	assert.Equal(t, 0, resp.Rate.Remaining)
+	assert.Equal(t, reset.Unix(), resp.Rate.Reset.Unix())
+
+	// Verify that an exhausted quota is rejected
+	_, _, err = c.RRSet.LookupName(ctx, "*.example.com", nil)
+	assert.True(t, dnsdb.IsQuotaExceeded(err))
+
+	// Verify that the API key is required
+	s.APIKey = "other"
+	_, _, err = c.RRSet.LookupName(ctx, "*.example.com", nil)
+	assert.True(t, dnsdb.IsUnauthorized(err))
+}
+
+func Test_Server_Paginate(t *testing.T) {
+	s := testServer()
+	defer s.Close()
+	c := s.Client()
+	c.NoResultsAsError = true
+	ctx := context.Background()
+
+	// Verify that the empty page after a total which is an exact multiple of the limit ends the results
+	expected, _, err := c.RRSet.LookupName(ctx, "*.example.com", nil)
+	assert.Nil(t, err)
+	var actual []dnsdb.RRSet
+	for r, err := range c.RRSet.PaginateName(ctx, "*.example.com", &dnsdb.RRSetLookupNameOptions{
+		LookupOptions: dnsdb.LookupOptions{Limit: 1},
+	}) {
+		if !assert.Nil(t, err) {
+			break
+		}
+		actual = append(actual, r)
+	}
+	assert.Equal(t, rrnames(expected), rrnames(actual))
+
+	// Verify that a query without any results is still an error
+	var lastErr error
+	for _, err := range c.RRSet.PaginateName(ctx, "*.example.org", nil) {
+		lastErr = err
+	}
+	assert.True(t, dnsdb.IsNoResults(lastErr))
+}
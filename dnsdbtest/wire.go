@@ -0,0 +1,84 @@
+package dnsdbtest
+
+// Imports
+import (
+	"encoding/binary"
+	"fmt"
+	"net/netip"
+	"strings"
+
+	"github.com/bored-engineer/go-dnsdb"
+)
+
+// parsePrefix parses the value of an rdata/ip lookup, either an address or a prefix in the addr,bits form
+func parsePrefix(s string) (netip.Prefix, error) {
+	if addr, bits, ok := strings.Cut(s, ","); ok {
+		return netip.ParsePrefix(addr + "/" + bits)
+	}
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
+// appendName appends the uncompressed wire form of a domain name
+func appendName(b []byte, name string) ([]byte, error) {
+	name = strings.TrimSuffix(strings.ToLower(name), ".")
+	if name != "" {
+		for _, label := range strings.Split(name, ".") {
+			if len(label) == 0 || len(label) > 63 {
+				return nil, fmt.Errorf("invalid name %q", name)
+			}
+			b = append(append(b, byte(len(label))), label...)
+		}
+	}
+	return append(b, 0), nil
+}
+
+// rdataWire returns the wire form of rdata in presentation format, for the types a raw lookup is likely to be used with
+func rdataWire(t dnsdb.RRType, rdata string) ([]byte, error) {
+	v, err := dnsdb.ParseRData(t, rdata)
+	if err != nil {
+		return nil, err
+	}
+	switch v := v.(type) {
+	case dnsdb.RDataA:
+		return v.Addr.AsSlice(), nil
+	case dnsdb.RDataAAAA:
+		return v.Addr.AsSlice(), nil
+	case dnsdb.RDataTarget:
+		return appendName(nil, v.Target)
+	case dnsdb.RDataMX:
+		return appendName(binary.BigEndian.AppendUint16(nil, v.Preference), v.Exchange)
+	case dnsdb.RDataSRV:
+		b := binary.BigEndian.AppendUint16(nil, v.Priority)
+		b = binary.BigEndian.AppendUint16(b, v.Weight)
+		b = binary.BigEndian.AppendUint16(b, v.Port)
+		return appendName(b, v.Target)
+	case dnsdb.RDataSOA:
+		b, err := appendName(nil, v.MName)
+		if err != nil {
+			return nil, err
+		}
+		if b, err = appendName(b, v.RName); err != nil {
+			return nil, err
+		}
+		for _, n := range []uint32{v.Serial, v.Refresh, v.Retry, v.Expire, v.Minimum} {
+			b = binary.BigEndian.AppendUint32(b, n)
+		}
+		return b, nil
+	case dnsdb.RDataTXT:
+		var b []byte
+		for _, seg := range v.Segments {
+			if len(seg) > 255 {
+				return nil, fmt.Errorf("segment too long")
+			}
+			b = append(append(b, byte(len(seg))), seg...)
+		}
+		return b, nil
+	case dnsdb.RDataGeneric:
+		return v.Data, nil
+	}
+	return nil, dnsdb.ErrUnsupportedRRType
+}
@@ -0,0 +1,432 @@
+package dnsdb
+
+// Imports
+import (
+	"bytes"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RDataDS is the rdata of a DS, CDS or DLV record
+type RDataDS struct {
+	RRType     RRType
+	KeyTag     uint16
+	Algorithm  uint8
+	DigestType uint8
+	Digest     []byte
+}
+
+// RDataDNSKEY is the rdata of a DNSKEY or CDNSKEY record
+type RDataDNSKEY struct {
+	RRType    RRType
+	Flags     uint16
+	Protocol  uint8
+	Algorithm uint8
+	PublicKey []byte
+}
+
+// RDataRRSIG is the rdata of an RRSIG record
+type RDataRRSIG struct {
+	TypeCovered RRType
+	Algorithm   uint8
+	Labels      uint8
+	OriginalTTL uint32
+	Expiration  time.Time
+	Inception   time.Time
+	KeyTag      uint16
+	SignerName  string
+	Signature   []byte
+}
+
+// RDataNSEC is the rdata of an NSEC record
+type RDataNSEC struct {
+	NextDomain string
+	Types      []RRType
+}
+
+// RDataNSEC3 is the rdata of an NSEC3 record
+type RDataNSEC3 struct {
+	HashAlgorithm   uint8
+	Flags           uint8
+	Iterations      uint16
+	Salt            []byte
+	NextHashedOwner []byte
+	Types           []RRType
+}
+
+// RDataNSEC3PARAM is the rdata of an NSEC3PARAM record
+type RDataNSEC3PARAM struct {
+	HashAlgorithm uint8
+	Flags         uint8
+	Iterations    uint16
+	Salt          []byte
+}
+
+func (r RDataDS) Type() RRType       { return r.RRType }
+func (r RDataDNSKEY) Type() RRType   { return r.RRType }
+func (RDataRRSIG) Type() RRType      { return RRTypeRRSIG }
+func (RDataNSEC) Type() RRType       { return RRTypeNSEC }
+func (RDataNSEC3) Type() RRType      { return RRTypeNSEC3 }
+func (RDataNSEC3PARAM) Type() RRType { return RRTypeNSEC3PARAM }
+
+// DS digest types as described at https://www.iana.org/assignments/ds-rr-types
+const (
+	DigestSHA1   uint8 = 1
+	DigestSHA256 uint8 = 2
+	DigestSHA384 uint8 = 4
+)
+
+// KeyTag computes the key tag of the key as described in RFC 4034 Appendix B
+func (k RDataDNSKEY) KeyTag() uint16 {
+	// Algorithm 1 (RSA/MD5) uses the most significant 16 bits of the last 24 bits of the modulus
+	if k.Algorithm == 1 {
+		if len(k.PublicKey) < 3 {
+			return 0
+		}
+		return binary.BigEndian.Uint16(k.PublicKey[len(k.PublicKey)-3:])
+	}
+	var ac uint32
+	for i, b := range k.wire() {
+		if i&1 == 0 {
+			ac += uint32(b) << 8
+		} else {
+			ac += uint32(b)
+		}
+	}
+	ac += ac >> 16 & 0xFFFF
+	return uint16(ac & 0xFFFF)
+}
+
+// SecureEntryPoint reports if the SEP flag is set, which conventionally marks a key signing key
+func (k RDataDNSKEY) SecureEntryPoint() bool {
+	return k.Flags&0x0001 != 0
+}
+
+// wire returns the wire format rdata of the key
+func (k RDataDNSKEY) wire() []byte {
+	wire := make([]byte, 4, 4+len(k.PublicKey))
+	binary.BigEndian.PutUint16(wire, k.Flags)
+	wire[2] = k.Protocol
+	wire[3] = k.Algorithm
+	return append(wire, k.PublicKey...)
+}
+
+// Digest computes the DS digest of the key for the provided owner name
+func (k RDataDNSKEY) Digest(owner string, digestType uint8) ([]byte, error) {
+	var h hash.Hash
+	switch digestType {
+	case DigestSHA1:
+		h = sha1.New()
+	case DigestSHA256:
+		h = sha256.New()
+	case DigestSHA384:
+		h = sha512.New384()
+	default:
+		return nil, fmt.Errorf("dnsdb: unsupported digest type %d", digestType)
+	}
+	name, err := canonicalName(owner)
+	if err != nil {
+		return nil, err
+	}
+	h.Write(name)
+	h.Write(k.wire())
+	return h.Sum(nil), nil
+}
+
+// Matches reports if the DS record refers to the key with the provided owner name
+func (d RDataDS) Matches(owner string, k RDataDNSKEY) bool {
+	if d.KeyTag != k.KeyTag() || d.Algorithm != k.Algorithm {
+		return false
+	}
+	digest, err := k.Digest(owner, d.DigestType)
+	return err == nil && bytes.Equal(digest, d.Digest)
+}
+
+// canonicalName returns the lowercase wire format of a presentation format domain name
+func canonicalName(name string) ([]byte, error) {
+	if name == "." {
+		return []byte{0}, nil
+	}
+	var wire []byte
+	var label []byte
+	flush := func() error {
+		if len(label) == 0 {
+			return fmt.Errorf("dnsdb: empty label in %q", name)
+		}
+		if len(label) > 63 {
+			return fmt.Errorf("dnsdb: label too long in %q", name)
+		}
+		wire = append(wire, byte(len(label)))
+		wire = append(wire, bytes.ToLower(label)...)
+		label = label[:0]
+		return nil
+	}
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		switch {
+		case c == '.':
+			if err := flush(); err != nil {
+				return nil, err
+			}
+		case c == '\\' && i+3 < len(name) && isDigit(name[i+1]) && isDigit(name[i+2]) && isDigit(name[i+3]):
+			n, _ := strconv.Atoi(name[i+1 : i+4])
+			if n > 255 {
+				return nil, fmt.Errorf("dnsdb: invalid escape in %q", name)
+			}
+			label = append(label, byte(n))
+			i += 3
+		case c == '\\' && i+1 < len(name):
+			label = append(label, name[i+1])
+			i++
+		default:
+			label = append(label, c)
+		}
+	}
+	if len(label) > 0 {
+		if err := flush(); err != nil {
+			return nil, err
+		}
+	}
+	return append(wire, 0), nil
+}
+
+// parseSigTime parses an RRSIG time in either YYYYMMDDHHmmSS or unix seconds form
+func parseSigTime(s string) (time.Time, error) {
+	if len(s) == 14 {
+		return time.ParseInLocation("20060102150405", s, time.UTC)
+	}
+	i, err := strconv.ParseUint(s, 10, 32)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(int64(i), 0).UTC(), nil
+}
+
+// parseTypes parses an NSEC type bitmap in presentation format
+func parseTypes(fields []string) ([]RRType, error) {
+	types := make([]RRType, 0, len(fields))
+	for _, f := range fields {
+		t := RRType(strings.ToUpper(f))
+		if err := t.Validate(); err != nil {
+			return nil, err
+		}
+		types = append(types, t)
+	}
+	return types, nil
+}
+
+// parseSalt parses an NSEC3 salt, where "-" is empty
+func parseSalt(s string) ([]byte, error) {
+	if s == "-" {
+		return []byte{}, nil
+	}
+	return hex.DecodeString(s)
+}
+
+// parseUints parses the leading fields as unsigned integers of the provided sizes
+func parseUints(fields []string, bits ...int) ([]uint64, error) {
+	if len(fields) < len(bits) {
+		return nil, errors.New("dnsdb: too few fields in rdata")
+	}
+	nums := make([]uint64, len(bits))
+	for i, b := range bits {
+		n, err := parseUint(fields, i, b)
+		if err != nil {
+			return nil, err
+		}
+		nums[i] = n
+	}
+	return nums, nil
+}
+
+// parseDNSSEC parses the fields of the DNSSEC record types, the bool is false if t is not one
+func parseDNSSEC(t RRType, fields []string) (RDataValue, bool, error) {
+	switch t {
+	case RRTypeDS, RRTypeCDS, RRTypeDLV:
+		nums, err := parseUints(fields, 16, 8, 8)
+		if err != nil {
+			return nil, true, err
+		}
+		digest, err := hex.DecodeString(strings.Join(fields[3:], ""))
+		if err != nil {
+			return nil, true, err
+		}
+		return RDataDS{RRType: t, KeyTag: uint16(nums[0]), Algorithm: uint8(nums[1]), DigestType: uint8(nums[2]), Digest: digest}, true, nil
+	case RRTypeDNSKEY, RRTypeCDNSKEY:
+		nums, err := parseUints(fields, 16, 8, 8)
+		if err != nil {
+			return nil, true, err
+		}
+		key, err := base64.StdEncoding.DecodeString(strings.Join(fields[3:], ""))
+		if err != nil {
+			return nil, true, err
+		}
+		return RDataDNSKEY{RRType: t, Flags: uint16(nums[0]), Protocol: uint8(nums[1]), Algorithm: uint8(nums[2]), PublicKey: key}, true, nil
+	case RRTypeRRSIG:
+		if len(fields) < 9 {
+			return nil, true, errors.New("dnsdb: too few fields in RRSIG rdata")
+		}
+		covered := RRType(strings.ToUpper(fields[0]))
+		if err := covered.Validate(); err != nil {
+			return nil, true, err
+		}
+		nums, err := parseUints(fields[1:], 8, 8, 32)
+		if err != nil {
+			return nil, true, err
+		}
+		expiration, err := parseSigTime(fields[4])
+		if err != nil {
+			return nil, true, err
+		}
+		inception, err := parseSigTime(fields[5])
+		if err != nil {
+			return nil, true, err
+		}
+		keyTag, err := parseUint(fields, 6, 16)
+		if err != nil {
+			return nil, true, err
+		}
+		sig, err := base64.StdEncoding.DecodeString(strings.Join(fields[8:], ""))
+		if err != nil {
+			return nil, true, err
+		}
+		return RDataRRSIG{
+			TypeCovered: covered,
+			Algorithm:   uint8(nums[0]),
+			Labels:      uint8(nums[1]),
+			OriginalTTL: uint32(nums[2]),
+			Expiration:  expiration,
+			Inception:   inception,
+			KeyTag:      uint16(keyTag),
+			SignerName:  fields[7],
+			Signature:   sig,
+		}, true, nil
+	case RRTypeNSEC:
+		if len(fields) < 1 {
+			return nil, true, errors.New("dnsdb: too few fields in NSEC rdata")
+		}
+		types, err := parseTypes(fields[1:])
+		if err != nil {
+			return nil, true, err
+		}
+		return RDataNSEC{NextDomain: fields[0], Types: types}, true, nil
+	case RRTypeNSEC3, RRTypeNSEC3PARAM:
+		nums, err := parseUints(fields, 8, 8, 16)
+		if err != nil {
+			return nil, true, err
+		}
+		if len(fields) < 4 {
+			return nil, true, errors.New("dnsdb: too few fields in NSEC3 rdata")
+		}
+		salt, err := parseSalt(fields[3])
+		if err != nil {
+			return nil, true, err
+		}
+		if t == RRTypeNSEC3PARAM {
+			if len(fields) != 4 {
+				return nil, true, errors.New("dnsdb: too many fields in NSEC3PARAM rdata")
+			}
+			return RDataNSEC3PARAM{HashAlgorithm: uint8(nums[0]), Flags: uint8(nums[1]), Iterations: uint16(nums[2]), Salt: salt}, true, nil
+		}
+		if len(fields) < 5 {
+			return nil, true, errors.New("dnsdb: too few fields in NSEC3 rdata")
+		}
+		next, err := base32.HexEncoding.WithPadding(base32.NoPadding).DecodeString(strings.ToUpper(fields[4]))
+		if err != nil {
+			return nil, true, err
+		}
+		types, err := parseTypes(fields[5:])
+		if err != nil {
+			return nil, true, err
+		}
+		return RDataNSEC3{HashAlgorithm: uint8(nums[0]), Flags: uint8(nums[1]), Iterations: uint16(nums[2]), Salt: salt, NextHashedOwner: next, Types: types}, true, nil
+	}
+	return nil, false, nil
+}
+
+// DSMatch reports which DNSKEY a DS record matched and when the two were seen together.
+// If no key ever matched the DS record DNSKEY is nil and the times are those of the DS record alone.
+type DSMatch struct {
+	Owner     string
+	DS        RDataDS
+	DNSKEY    *RDataDNSKEY
+	TimeFirst time.Time
+	TimeLast  time.Time
+}
+
+// MatchDS compares the DS records of a zone as seen in its parent against the DNSKEY records of the zone.
+// Every DS rdata is reported once for each DNSKEY it matched while both rrsets were observed at the same time,
+// or once with a nil DNSKEY if it never matched any key.
+func MatchDS(ds []RRSet, dnskey []RRSet) ([]DSMatch, error) {
+	var matches []DSMatch
+	for _, dsSet := range ds {
+		if dsSet.RRName == nil {
+			continue
+		}
+		dsFirst, dsLast, _ := span(dsSet.TimeFirst, dsSet.TimeLast, dsSet.ZoneTimeFirst, dsSet.ZoneTimeLast)
+		values, err := dsSet.Values()
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range values {
+			d, ok := v.(RDataDS)
+			if !ok {
+				continue
+			}
+			matched := false
+			for _, keySet := range dnskey {
+				if keySet.RRName == nil || !strings.EqualFold(strings.TrimSuffix(*keySet.RRName, "."), strings.TrimSuffix(*dsSet.RRName, ".")) {
+					continue
+				}
+				keyFirst, keyLast, _ := span(keySet.TimeFirst, keySet.TimeLast, keySet.ZoneTimeFirst, keySet.ZoneTimeLast)
+				first, last := laterOf(dsFirst, keyFirst), earlierOf(dsLast, keyLast)
+				if last.Before(first) {
+					continue
+				}
+				keys, err := keySet.Values()
+				if err != nil {
+					return nil, err
+				}
+				for _, kv := range keys {
+					k, ok := kv.(RDataDNSKEY)
+					if !ok || !d.Matches(*dsSet.RRName, k) {
+						continue
+					}
+					matched = true
+					matches = append(matches, DSMatch{Owner: *dsSet.RRName, DS: d, DNSKEY: &k, TimeFirst: first, TimeLast: last})
+				}
+			}
+			if !matched {
+				matches = append(matches, DSMatch{Owner: *dsSet.RRName, DS: d, TimeFirst: dsFirst, TimeLast: dsLast})
+			}
+		}
+	}
+	return matches, nil
+}
+
+// laterOf returns the later of two times
+func laterOf(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+// earlierOf returns the earlier of two times
+func earlierOf(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
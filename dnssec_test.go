@@ -0,0 +1,142 @@
+package dnsdb
+
+import (
+	"github.com/stretchr/testify/assert"
+
+	"encoding/hex"
+	"testing"
+	"time"
+)
+
+// The example key from RFC 4034 section 5.4 and its digests from RFC 4034 and RFC 4509
+const (
+	testDNSKEY    = "256 3 5 AQOeiiR0GOMYkDshWoSKz9XzfwJr1AYtsmx3TGkJaNXVbfi/ 2pHm822aJ5iI9BMzNXxeYCmZDRD99WYwYqUSdjMmmAphXdvxegXd/M5+X7OrzKBaMbCVdFLUUh6DhweJBjEVv5f2wwjM9XzcnOf+EPbtG9DMBmADjFDc2w/rljwvFw=="
+	testDSSHA1    = "60485 5 1 2BB183AF5F22588179A53B0A 98631FAD1A292118"
+	testDSSHA256  = "60485 5 2 D4B7D520E7BB5F0F67674A0CCEB1E3E0614B93C4F9E99B8383F6A1E4469DA50A"
+	testDSUnknown = "12345 8 2 D4B7D520E7BB5F0F67674A0CCEB1E3E0614B93C4F9E99B8383F6A1E4469DA50A"
+)
+
+func Test_ParseRData_DNSSEC(t *testing.T) {
+	v, err := ParseRData(RRTypeDNSKEY, testDNSKEY)
+	assert.Nil(t, err)
+	key := v.(RDataDNSKEY)
+	assert.Equal(t, uint16(256), key.Flags)
+	assert.Equal(t, uint8(5), key.Algorithm)
+	assert.Equal(t, uint16(60485), key.KeyTag())
+	assert.False(t, key.SecureEntryPoint())
+
+	v, err = ParseRData(RRTypeDS, testDSSHA1)
+	assert.Nil(t, err)
+	ds := v.(RDataDS)
+	digest, _ := hex.DecodeString("2BB183AF5F22588179A53B0A98631FAD1A292118")
+	assert.Equal(t, RDataDS{RRType: RRTypeDS, KeyTag: 60485, Algorithm: 5, DigestType: DigestSHA1, Digest: digest}, ds)
+	assert.True(t, ds.Matches("dskey.example.com.", key))
+	assert.True(t, ds.Matches("DSKEY.Example.COM", key))
+	assert.False(t, ds.Matches("other.example.com.", key))
+
+	v, err = ParseRData(RRTypeDS, testDSSHA256)
+	assert.Nil(t, err)
+	assert.True(t, v.(RDataDS).Matches("dskey.example.com.", key))
+
+	v, err = ParseRData(RRTypeRRSIG, "A 5 3 86400 20030322173103 1048354263 2642 example.com. oJB1W6WNGv+ldvQ3WDG0MQkg5IEhjRip8WTr PYGv07h108dUKGMeDPKijVCHX3DDKdfb+v6o B9wfuh3DTJXUAfI=")
+	assert.Nil(t, err)
+	sig := v.(RDataRRSIG)
+	assert.Equal(t, RRTypeA, sig.TypeCovered)
+	assert.Equal(t, uint32(86400), sig.OriginalTTL)
+	assert.Equal(t, time.Date(2003, 3, 22, 17, 31, 3, 0, time.UTC), sig.Expiration)
+	assert.Equal(t, time.Unix(1048354263, 0).UTC(), sig.Inception)
+	assert.Equal(t, uint16(2642), sig.KeyTag)
+	assert.Equal(t, "example.com.", sig.SignerName)
+
+	v, err = ParseRData(RRTypeNSEC, "host.example.com. A MX RRSIG NSEC TYPE1234")
+	assert.Nil(t, err)
+	assert.Equal(t, RDataNSEC{NextDomain: "host.example.com.", Types: []RRType{"A", "MX", "RRSIG", "NSEC", "TYPE1234"}}, v)
+
+	v, err = ParseRData(RRTypeNSEC3, "1 1 12 aabbccdd 2t7b4g4vsa5smi47k61mv5bv1a22bojr MX DNSKEY NS SOA NSEC3PARAM RRSIG")
+	assert.Nil(t, err)
+	nsec3 := v.(RDataNSEC3)
+	assert.Equal(t, uint16(12), nsec3.Iterations)
+	assert.Equal(t, []byte{0xaa, 0xbb, 0xcc, 0xdd}, nsec3.Salt)
+	assert.Len(t, nsec3.NextHashedOwner, 20)
+	assert.Len(t, nsec3.Types, 6)
+
+	v, err = ParseRData(RRTypeNSEC3PARAM, "1 0 0 -")
+	assert.Nil(t, err)
+	assert.Equal(t, RDataNSEC3PARAM{HashAlgorithm: 1, Salt: []byte{}}, v)
+
+	for _, test := range []struct {
+		RRType RRType
+		RData  string
+	}{
+		{RRTypeDS, "60485 5"},
+		{RRTypeDS, "60485 5 1 ZZ"},
+		{RRTypeDNSKEY, "256 3 5 !!!"},
+		{RRTypeRRSIG, "A 5 3 86400 soon 1048354263 2642 example.com. oJB1"},
+		{RRTypeNSEC, "host.example.com. BOGUS"},
+		{RRTypeNSEC3PARAM, "1 0 0 - extra"},
+	} {
+		_, err := ParseRData(test.RRType, test.RData)
+		assert.NotNil(t, err, test.RData)
+	}
+}
+
+func Test_MatchDS(t *testing.T) {
+	ds := []RRSet{
+		RRSet{
+			RRName:    String("dskey.example.com."),
+			RRType:    String("DS"),
+			TimeFirst: NewTimestamp(100),
+			TimeLast:  NewTimestamp(300),
+			RData:     []string{testDSSHA256, testDSUnknown},
+		},
+	}
+	dnskey := []RRSet{
+		RRSet{
+			RRName:    String("dskey.example.com."),
+			RRType:    String("DNSKEY"),
+			TimeFirst: NewTimestamp(200),
+			TimeLast:  NewTimestamp(400),
+			RData:     []string{testDNSKEY},
+		},
+		RRSet{
+			RRName:    String("dskey.example.com."),
+			RRType:    String("DNSKEY"),
+			TimeFirst: NewTimestamp(500),
+			TimeLast:  NewTimestamp(600),
+			RData:     []string{testDNSKEY},
+		},
+	}
+	matches, err := MatchDS(ds, dnskey)
+	assert.Nil(t, err)
+	assert.Len(t, matches, 2)
+
+	// The SHA-256 DS matched the key while both were seen
+	assert.Equal(t, uint16(60485), matches[0].DS.KeyTag)
+	assert.NotNil(t, matches[0].DNSKEY)
+	assert.Equal(t, uint16(60485), matches[0].DNSKEY.KeyTag())
+	assert.Equal(t, time.Unix(200, 0), matches[0].TimeFirst)
+	assert.Equal(t, time.Unix(300, 0), matches[0].TimeLast)
+
+	// The unknown DS never matched
+	assert.Equal(t, uint16(12345), matches[1].DS.KeyTag)
+	assert.Nil(t, matches[1].DNSKEY)
+	assert.Equal(t, time.Unix(100, 0), matches[1].TimeFirst)
+	assert.Equal(t, time.Unix(300, 0), matches[1].TimeLast)
+
+	// Invalid rdata fails
+	_, err = MatchDS([]RRSet{RRSet{RRName: String("a."), RRType: String("DS"), RData: []string{"bad"}}}, nil)
+	assert.NotNil(t, err)
+}
+
+func Test_canonicalName(t *testing.T) {
+	wire, err := canonicalName("WWW.Example\\046com.")
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("\x03www\x0bexample.com\x00"), wire)
+
+	wire, err = canonicalName(".")
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{0}, wire)
+
+	_, err = canonicalName("a..b")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,432 @@
+package dnsdb
+
+// Imports
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"iter"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Encoder writes RRSet and RData results in a specific format, Flush must be called once all results are written
+type Encoder interface {
+	EncodeRRSet(RRSet) error
+	EncodeRData(RData) error
+	Flush() error
+}
+
+// TrailingDot controls how owner names and bailiwicks are written by an Encoder
+type TrailingDot int
+
+// The TrailingDot modes
+const (
+	TrailingDotKeep  TrailingDot = iota // As returned by the API
+	TrailingDotAdd                      // Always fully qualified
+	TrailingDotStrip                    // Never fully qualified
+)
+
+// EncoderOptions specifies the optional parameters to the New*Encoder functions
+type EncoderOptions struct {
+	// TimeFormat is a time.Time.Format layout for times, unix timestamps are written if empty
+	TimeFormat string
+
+	// Location to convert times to before formatting, defaults to UTC
+	Location *time.Location
+
+	// TrailingDot controls the trailing dot of rrname and bailiwick, rdata is always written as returned
+	TrailingDot TrailingDot
+}
+
+// name applies the TrailingDot mode to an optional owner name or bailiwick, returning the empty string if it is nil
+func (opt *EncoderOptions) name(s *string) string {
+	if s == nil {
+		return ""
+	}
+	switch opt.TrailingDot {
+	case TrailingDotAdd:
+		if !strings.HasSuffix(*s, ".") {
+			return *s + "."
+		}
+	case TrailingDotStrip:
+		if *s != "." {
+			return strings.TrimSuffix(*s, ".")
+		}
+	}
+	return *s
+}
+
+// time formats a timestamp, returning the empty string if it is nil
+func (opt *EncoderOptions) time(t *Timestamp) string {
+	if t == nil {
+		return ""
+	}
+	if opt.TimeFormat == "" {
+		return strconv.FormatInt(t.Unix(), 10)
+	}
+	loc := opt.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	return t.In(loc).Format(opt.TimeFormat)
+}
+
+// record is the common shape of RRSet and RData used by the encoders
+type record struct {
+	Count                                            *uint64
+	Bailiwick                                        *string
+	TimeFirst, TimeLast, ZoneTimeFirst, ZoneTimeLast *Timestamp
+	RRName, RRType                                   *string
+	RData                                            []string
+}
+
+func rrsetRecord(r RRSet) record {
+	return record{r.Count, r.Bailiwick, r.TimeFirst, r.TimeLast, r.ZoneTimeFirst, r.ZoneTimeLast, r.RRName, r.RRType, r.RData}
+}
+
+func rdataRecord(r RData) record {
+	var rdata []string
+	if r.RData != nil {
+		rdata = []string{*r.RData}
+	}
+	return record{r.Count, nil, r.TimeFirst, r.TimeLast, r.ZoneTimeFirst, r.ZoneTimeLast, r.RRName, r.RRType, rdata}
+}
+
+// All returns a stream over the values of a slice, for passing results from a Lookup method to EncodeRRSets or EncodeRData
+func All[T any](s []T) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for _, v := range s {
+			if !yield(v, nil) {
+				return
+			}
+		}
+	}
+}
+
+// EncodeRRSets writes every RRSet of a stream, stopping at the first error
+func EncodeRRSets(e Encoder, seq iter.Seq2[RRSet, error]) error {
+	return encodeAll(seq, e.EncodeRRSet)
+}
+
+// EncodeRData writes every RData of a stream, stopping at the first error
+func EncodeRData(e Encoder, seq iter.Seq2[RData, error]) error {
+	return encodeAll(seq, e.EncodeRData)
+}
+
+// encodeAll is a helper function for EncodeRRSets and EncodeRData
+func encodeAll[T any](seq iter.Seq2[T, error], encode func(T) error) error {
+	for v, err := range seq {
+		if err != nil {
+			return err
+		}
+		if err := encode(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PresentationEncoder writes results like dig, each record is preceded by ;; comments with its count and times
+// and followed by a blank line.
+type PresentationEncoder struct {
+	w   *bufio.Writer
+	opt EncoderOptions
+}
+
+// NewPresentationEncoder returns a PresentationEncoder writing to w, opt may be nil.
+// Times are written in RFC 3339 format unless opt.TimeFormat is set.
+func NewPresentationEncoder(w io.Writer, opt *EncoderOptions) *PresentationEncoder {
+	e := &PresentationEncoder{w: bufio.NewWriter(w)}
+	if opt != nil {
+		e.opt = *opt
+	}
+	if e.opt.TimeFormat == "" {
+		e.opt.TimeFormat = time.RFC3339
+	}
+	return e
+}
+
+func (e *PresentationEncoder) encode(r record) error {
+	if r.TimeFirst != nil || r.TimeLast != nil {
+		fmt.Fprintf(e.w, ";; first seen: %s\n;;  last seen: %s\n", e.opt.time(r.TimeFirst), e.opt.time(r.TimeLast))
+	}
+	if r.ZoneTimeFirst != nil || r.ZoneTimeLast != nil {
+		fmt.Fprintf(e.w, ";; first seen in zone file: %s\n;;  last seen in zone file: %s\n",
+			e.opt.time(r.ZoneTimeFirst), e.opt.time(r.ZoneTimeLast))
+	}
+	if r.Count != nil {
+		fmt.Fprintf(e.w, ";; count: %d", *r.Count)
+		if r.Bailiwick != nil {
+			fmt.Fprintf(e.w, "; bailiwick: %s", e.opt.name(r.Bailiwick))
+		}
+		e.w.WriteString("\n")
+	} else if r.Bailiwick != nil {
+		fmt.Fprintf(e.w, ";; bailiwick: %s\n", e.opt.name(r.Bailiwick))
+	}
+	name := e.opt.name(r.RRName)
+	for _, rdata := range r.RData {
+		fmt.Fprintf(e.w, "%s  %s  %s\n", name, deref(r.RRType), rdata)
+	}
+	_, err := e.w.WriteString("\n")
+	return err
+}
+
+// EncodeRRSet implements the Encoder interface
+func (e *PresentationEncoder) EncodeRRSet(r RRSet) error { return e.encode(rrsetRecord(r)) }
+
+// EncodeRData implements the Encoder interface
+func (e *PresentationEncoder) EncodeRData(r RData) error { return e.encode(rdataRecord(r)) }
+
+// Flush implements the Encoder interface
+func (e *PresentationEncoder) Flush() error { return e.w.Flush() }
+
+// TextEncoder writes one line per rdata value with the owner name, type and rdata separated by spaces, any counts
+// and times are omitted.
+type TextEncoder struct {
+	w   *bufio.Writer
+	opt EncoderOptions
+}
+
+// NewTextEncoder returns a TextEncoder writing to w, opt may be nil
+func NewTextEncoder(w io.Writer, opt *EncoderOptions) *TextEncoder {
+	e := &TextEncoder{w: bufio.NewWriter(w)}
+	if opt != nil {
+		e.opt = *opt
+	}
+	return e
+}
+
+func (e *TextEncoder) encode(r record) error {
+	name := e.opt.name(r.RRName)
+	for _, rdata := range r.RData {
+		if _, err := fmt.Fprintf(e.w, "%s %s %s\n", name, deref(r.RRType), rdata); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// EncodeRRSet implements the Encoder interface
+func (e *TextEncoder) EncodeRRSet(r RRSet) error { return e.encode(rrsetRecord(r)) }
+
+// EncodeRData implements the Encoder interface
+func (e *TextEncoder) EncodeRData(r RData) error { return e.encode(rdataRecord(r)) }
+
+// Flush implements the Encoder interface
+func (e *TextEncoder) Flush() error { return e.w.Flush() }
+
+// csvHeader is the column set written by CSVEncoder
+var csvHeader = []string{
+	"time_first", "time_last", "zone_time_first", "zone_time_last", "count", "bailiwick", "rrname", "rrtype", "rdata",
+}
+
+// CSVEncoder writes results as CSV (or TSV) with a header row, an RRSet is written as one row per rdata value.
+// Absent fields are written as empty columns so the column set is the same for every result.
+type CSVEncoder struct {
+	w      rowWriter
+	opt    EncoderOptions
+	header bool
+}
+
+// rowWriter writes the rows of a CSVEncoder, it is implemented by csv.Writer and tsvWriter
+type rowWriter interface {
+	Write(record []string) error
+	Flush()
+	Error() error
+}
+
+// tsvEscaper escapes the characters which would otherwise split a TSV field
+var tsvEscaper = strings.NewReplacer(`\`, `\\`, "\t", `\t`, "\n", `\n`, "\r", `\r`)
+
+// tsvWriter writes tab separated rows. Unlike CSV fields are never quoted, instead backslashes, tabs and newlines
+// within a field are escaped as \\, \t, \n and \r.
+type tsvWriter struct {
+	w   *bufio.Writer
+	err error
+}
+
+// Write implements the rowWriter interface
+func (w *tsvWriter) Write(record []string) error {
+	for i, field := range record {
+		if i > 0 {
+			w.w.WriteByte('\t')
+		}
+		w.w.WriteString(tsvEscaper.Replace(field))
+	}
+	return w.w.WriteByte('\n')
+}
+
+// Flush implements the rowWriter interface
+func (w *tsvWriter) Flush() { w.err = w.w.Flush() }
+
+// Error implements the rowWriter interface
+func (w *tsvWriter) Error() error { return w.err }
+
+// NewCSVEncoder returns a comma separated CSVEncoder writing to w, opt may be nil
+func NewCSVEncoder(w io.Writer, opt *EncoderOptions) *CSVEncoder {
+	e := &CSVEncoder{w: csv.NewWriter(w)}
+	if opt != nil {
+		e.opt = *opt
+	}
+	return e
+}
+
+// NewTSVEncoder returns a tab separated CSVEncoder writing to w, opt may be nil.
+// Fields are escaped rather than quoted, see tsvWriter.
+func NewTSVEncoder(w io.Writer, opt *EncoderOptions) *CSVEncoder {
+	e := NewCSVEncoder(w, opt)
+	e.w = &tsvWriter{w: bufio.NewWriter(w)}
+	return e
+}
+
+// writeHeader writes the header row if it has not been written yet
+func (e *CSVEncoder) writeHeader() error {
+	if e.header {
+		return nil
+	}
+	e.header = true
+	return e.w.Write(csvHeader)
+}
+
+func (e *CSVEncoder) encode(r record) error {
+	if err := e.writeHeader(); err != nil {
+		return err
+	}
+	var count string
+	if r.Count != nil {
+		count = strconv.FormatUint(*r.Count, 10)
+	}
+	row := []string{
+		e.opt.time(r.TimeFirst), e.opt.time(r.TimeLast), e.opt.time(r.ZoneTimeFirst), e.opt.time(r.ZoneTimeLast),
+		count, e.opt.name(r.Bailiwick), e.opt.name(r.RRName), deref(r.RRType), "",
+	}
+	// A record without rdata still gets a row so that it is not silently dropped
+	if len(r.RData) == 0 {
+		return e.w.Write(row)
+	}
+	for _, rdata := range r.RData {
+		row[len(row)-1] = rdata
+		if err := e.w.Write(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// EncodeRRSet implements the Encoder interface
+func (e *CSVEncoder) EncodeRRSet(r RRSet) error { return e.encode(rrsetRecord(r)) }
+
+// EncodeRData implements the Encoder interface
+func (e *CSVEncoder) EncodeRData(r RData) error { return e.encode(rdataRecord(r)) }
+
+// Flush implements the Encoder interface, the header row is written even if there were no results
+func (e *CSVEncoder) Flush() error {
+	if err := e.writeHeader(); err != nil {
+		return err
+	}
+	e.w.Flush()
+	return e.w.Error()
+}
+
+// NDJSONEncoder writes one JSON object per result in the API's own format and field order, omitting absent fields.
+// Times are unix timestamps unless a TimeFormat is set.
+type NDJSONEncoder struct {
+	w   *bufio.Writer
+	opt EncoderOptions
+}
+
+// NewNDJSONEncoder returns a NDJSONEncoder writing to w, opt may be nil
+func NewNDJSONEncoder(w io.Writer, opt *EncoderOptions) *NDJSONEncoder {
+	e := &NDJSONEncoder{w: bufio.NewWriter(w)}
+	if opt != nil {
+		e.opt = *opt
+	}
+	return e
+}
+
+// jsonTime formats a timestamp as a JSON number or string
+func (e *NDJSONEncoder) jsonTime(t *Timestamp) json.RawMessage {
+	if t == nil {
+		return nil
+	}
+	s := e.opt.time(t)
+	if e.opt.TimeFormat == "" {
+		return json.RawMessage(s)
+	}
+	b, _ := json.Marshal(s)
+	return b
+}
+
+// jsonName applies the TrailingDot mode to an optional name
+func (e *NDJSONEncoder) jsonName(s *string) *string {
+	if s == nil {
+		return nil
+	}
+	name := e.opt.name(s)
+	return &name
+}
+
+// jsonRecord is the field order of a result written by NDJSONEncoder
+type jsonRecord struct {
+	Count         *uint64         `json:"count,omitempty"`
+	TimeFirst     json.RawMessage `json:"time_first,omitempty"`
+	TimeLast      json.RawMessage `json:"time_last,omitempty"`
+	ZoneTimeFirst json.RawMessage `json:"zone_time_first,omitempty"`
+	ZoneTimeLast  json.RawMessage `json:"zone_time_last,omitempty"`
+	RRName        *string         `json:"rrname,omitempty"`
+	RRType        *string         `json:"rrtype,omitempty"`
+	Bailiwick     *string         `json:"bailiwick,omitempty"`
+	RData         interface{}     `json:"rdata,omitempty"`
+}
+
+func (e *NDJSONEncoder) encode(r record, rdata interface{}) error {
+	b, err := json.Marshal(jsonRecord{
+		Count:         r.Count,
+		TimeFirst:     e.jsonTime(r.TimeFirst),
+		TimeLast:      e.jsonTime(r.TimeLast),
+		ZoneTimeFirst: e.jsonTime(r.ZoneTimeFirst),
+		ZoneTimeLast:  e.jsonTime(r.ZoneTimeLast),
+		RRName:        e.jsonName(r.RRName),
+		RRType:        r.RRType,
+		Bailiwick:     e.jsonName(r.Bailiwick),
+		RData:         rdata,
+	})
+	if err != nil {
+		return err
+	}
+	e.w.Write(b)
+	return e.w.WriteByte('\n')
+}
+
+// EncodeRRSet implements the Encoder interface
+func (e *NDJSONEncoder) EncodeRRSet(r RRSet) error {
+	var rdata interface{}
+	if r.RData != nil {
+		rdata = r.RData
+	}
+	return e.encode(rrsetRecord(r), rdata)
+}
+
+// EncodeRData implements the Encoder interface
+func (e *NDJSONEncoder) EncodeRData(r RData) error {
+	var rdata interface{}
+	if r.RData != nil {
+		rdata = *r.RData
+	}
+	return e.encode(rdataRecord(r), rdata)
+}
+
+// Flush implements the Encoder interface
+func (e *NDJSONEncoder) Flush() error { return e.w.Flush() }
+
+// deref dereferences an optional string
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
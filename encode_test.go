@@ -0,0 +1,122 @@
+package dnsdb
+
+import (
+	"github.com/stretchr/testify/assert"
+
+	"bytes"
+	"errors"
+	"iter"
+	"testing"
+)
+
+// testEncodeRRSet is a fixture for the encoder tests
+var testEncodeRRSet = RRSet{
+	Count:     Uint64(5059),
+	TimeFirst: NewTimestamp(1380139330),
+	TimeLast:  NewTimestamp(1427881899),
+	RRName:    String("www.farsightsecurity.com."),
+	RRType:    String("A"),
+	Bailiwick: String("farsightsecurity.com."),
+	RData:     []string{"66.160.140.81", "104.244.13.104"},
+}
+
+// testEncodeRData is a fixture for the encoder tests
+var testEncodeRData = RData{
+	Count:         Uint64(2),
+	ZoneTimeFirst: NewTimestamp(1380139330),
+	ZoneTimeLast:  NewTimestamp(1380139330),
+	RRName:        String("fsi.io"),
+	RRType:        String("MX"),
+	RData:         String("10 hq.fsi.io."),
+}
+
+func Test_PresentationEncoder(t *testing.T) {
+	// Verify that records are preceded by their times and count
+	var buf bytes.Buffer
+	e := NewPresentationEncoder(&buf, &EncoderOptions{TrailingDot: TrailingDotAdd})
+	assert.Nil(t, EncodeRRSets(e, All([]RRSet{testEncodeRRSet})))
+	assert.Nil(t, e.EncodeRData(testEncodeRData))
+	assert.Nil(t, e.Flush())
+	assert.Equal(t, `;; first seen: 2013-09-25T20:02:10Z
+;;  last seen: 2015-04-01T09:51:39Z
+;; count: 5059; bailiwick: farsightsecurity.com.
+www.farsightsecurity.com.  A  66.160.140.81
+www.farsightsecurity.com.  A  104.244.13.104
+
+;; first seen in zone file: 2013-09-25T20:02:10Z
+;;  last seen in zone file: 2013-09-25T20:02:10Z
+;; count: 2
+fsi.io.  MX  10 hq.fsi.io.
+
+`, buf.String())
+}
+
+func Test_TextEncoder(t *testing.T) {
+	// Verify that each rdata value is written as a line with the owner name and type
+	var buf bytes.Buffer
+	e := NewTextEncoder(&buf, &EncoderOptions{TrailingDot: TrailingDotStrip})
+	assert.Nil(t, e.EncodeRRSet(testEncodeRRSet))
+	assert.Nil(t, e.EncodeRData(testEncodeRData))
+	assert.Nil(t, e.Flush())
+	assert.Equal(t, `www.farsightsecurity.com A 66.160.140.81
+www.farsightsecurity.com A 104.244.13.104
+fsi.io MX 10 hq.fsi.io.
+`, buf.String())
+}
+
+func Test_CSVEncoder(t *testing.T) {
+	// Verify that an RRSet is written as a row per rdata
+	var buf bytes.Buffer
+	e := NewCSVEncoder(&buf, &EncoderOptions{TrailingDot: TrailingDotStrip})
+	assert.Nil(t, e.EncodeRRSet(testEncodeRRSet))
+	assert.Nil(t, e.EncodeRData(testEncodeRData))
+	assert.Nil(t, e.Flush())
+	assert.Equal(t, `time_first,time_last,zone_time_first,zone_time_last,count,bailiwick,rrname,rrtype,rdata
+1380139330,1427881899,,,5059,farsightsecurity.com,www.farsightsecurity.com,A,66.160.140.81
+1380139330,1427881899,,,5059,farsightsecurity.com,www.farsightsecurity.com,A,104.244.13.104
+,,1380139330,1380139330,2,,fsi.io,MX,10 hq.fsi.io.
+`, buf.String())
+
+	// Verify that TSV uses tabs and the header is written without results
+	buf.Reset()
+	e = NewTSVEncoder(&buf, nil)
+	assert.Nil(t, e.Flush())
+	header := "time_first\ttime_last\tzone_time_first\tzone_time_last\tcount\tbailiwick\trrname\trrtype\trdata\n"
+	assert.Equal(t, header, buf.String())
+
+	// Verify that TSV fields are escaped rather than quoted
+	buf.Reset()
+	e = NewTSVEncoder(&buf, nil)
+	assert.Nil(t, e.EncodeRData(RData{RRName: String("fsi.io."), RRType: String("TXT"), RData: String("\"a\tb\\c\"")}))
+	assert.Nil(t, e.Flush())
+	assert.Equal(t, header+"\t\t\t\t\t\tfsi.io.\tTXT\t\"a\\tb\\\\c\"\n", buf.String())
+
+	// Verify that a record without rdata is still written as a row
+	buf.Reset()
+	e = NewCSVEncoder(&buf, nil)
+	assert.Nil(t, e.EncodeRRSet(RRSet{RRName: String("fsi.io."), RRType: String("A")}))
+	assert.Nil(t, e.Flush())
+	assert.Equal(t, "time_first,time_last,zone_time_first,zone_time_last,count,bailiwick,rrname,rrtype,rdata\n,,,,,,fsi.io.,A,\n", buf.String())
+}
+
+func Test_NDJSONEncoder(t *testing.T) {
+	// Verify that results are written in the API field order
+	var buf bytes.Buffer
+	e := NewNDJSONEncoder(&buf, nil)
+	assert.Nil(t, e.EncodeRRSet(testEncodeRRSet))
+	assert.Nil(t, e.Flush())
+	assert.Equal(t, `{"count":5059,"time_first":1380139330,"time_last":1427881899,"rrname":"www.farsightsecurity.com.","rrtype":"A","bailiwick":"farsightsecurity.com.","rdata":["66.160.140.81","104.244.13.104"]}`+"\n", buf.String())
+
+	// Verify that times can be formatted
+	buf.Reset()
+	e = NewNDJSONEncoder(&buf, &EncoderOptions{TimeFormat: "2006-01-02"})
+	assert.Nil(t, e.EncodeRData(testEncodeRData))
+	assert.Nil(t, e.Flush())
+	assert.Equal(t, `{"count":2,"zone_time_first":"2013-09-25","zone_time_last":"2013-09-25","rrname":"fsi.io","rrtype":"MX","rdata":"10 hq.fsi.io."}`+"\n", buf.String())
+
+	// Verify that stream errors are returned
+	var seq iter.Seq2[RData, error] = func(yield func(RData, error) bool) {
+		yield(RData{}, errors.New("boom"))
+	}
+	assert.EqualError(t, EncodeRData(e, seq), "boom")
+}
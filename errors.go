@@ -0,0 +1,93 @@
+package dnsdb
+
+// Imports
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Sentinel errors which an *ErrorResponse matches with errors.Is
+var (
+	ErrQuotaExceeded = errors.New("dnsdb: quota exceeded")
+	ErrUnauthorized  = errors.New("dnsdb: unauthorized")
+	ErrNoResults     = errors.New("dnsdb: no results found")
+)
+
+// maxErrorBody is the most of an error response body that is read for the message
+const maxErrorBody = 64 * 1024
+
+// ErrorResponse reports an error response returned by the DNSDB API
+type ErrorResponse struct {
+	StatusCode int           // HTTP status code of the response
+	Message    string        // Error message returned by the API, without the "Error: " prefix
+	Rate       Rate          // Rate at the time of the error
+	Request    *http.Request // Request which caused the error
+}
+
+// Error implements the error interface
+func (r *ErrorResponse) Error() string {
+	if r.Request == nil {
+		return fmt.Sprintf("dnsdb: %d %s", r.StatusCode, r.Message)
+	}
+	return fmt.Sprintf("dnsdb: %s %s: %d %s", r.Request.Method, r.Request.URL.EscapedPath(), r.StatusCode, r.Message)
+}
+
+// Is allows matching the sentinel errors with errors.Is
+func (r *ErrorResponse) Is(target error) bool {
+	switch target {
+	case ErrQuotaExceeded:
+		return r.StatusCode == http.StatusTooManyRequests
+	case ErrUnauthorized:
+		return r.StatusCode == http.StatusUnauthorized || r.StatusCode == http.StatusForbidden
+	case ErrNoResults:
+		return r.StatusCode == http.StatusNotFound && strings.Contains(strings.ToLower(r.Message), "no results")
+	}
+	return false
+}
+
+// newErrorResponse reads and closes the body of a failed response to build an ErrorResponse
+func newErrorResponse(resp *Response) *ErrorResponse {
+	defer resp.Body.Close()
+	e := &ErrorResponse{
+		StatusCode: resp.StatusCode,
+		Rate:       resp.Rate,
+		Request:    resp.Request,
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	var decoded struct {
+		Error string `json:"error"`
+		Msg   string `json:"msg"`
+	}
+	if json.Unmarshal(body, &decoded) == nil && (decoded.Error != "" || decoded.Msg != "") {
+		e.Message = decoded.Error
+		if e.Message == "" {
+			e.Message = decoded.Msg
+		}
+	} else {
+		e.Message = strings.TrimSpace(string(body))
+	}
+	e.Message = strings.TrimPrefix(e.Message, "Error: ")
+	if e.Message == "" {
+		e.Message = http.StatusText(resp.StatusCode)
+	}
+	return e
+}
+
+// IsQuotaExceeded reports if err was caused by exhausting the quota
+func IsQuotaExceeded(err error) bool {
+	return errors.Is(err, ErrQuotaExceeded)
+}
+
+// IsUnauthorized reports if err was caused by a missing or invalid API key
+func IsUnauthorized(err error) bool {
+	return errors.Is(err, ErrUnauthorized)
+}
+
+// IsNoResults reports if err was caused by a query with no results
+func IsNoResults(err error) bool {
+	return errors.Is(err, ErrNoResults)
+}
@@ -0,0 +1,86 @@
+package dnsdb
+
+import (
+	"github.com/stretchr/testify/assert"
+
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func Test_ErrorResponse(t *testing.T) {
+	// Setup a client
+	c := NewClient(nil)
+
+	// A server which returns the configured error
+	var status int
+	var body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Limit", "1000")
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", "1433980800")
+		w.WriteHeader(status)
+		io.WriteString(w, body)
+	}))
+	defer server.Close()
+	u, err := url.Parse(server.URL)
+	assert.Nil(t, err)
+	c.BaseURL = u
+
+	// Verify that a quota error is decoded
+	status, body = 429, "Error: Rate limit exceeded\n"
+	_, resp, err := c.RRSet.LookupName(context.Background(), "fsi.io", nil)
+	assert.NotNil(t, resp)
+	var errResp *ErrorResponse
+	assert.True(t, errors.As(err, &errResp))
+	assert.Equal(t, 429, errResp.StatusCode)
+	assert.Equal(t, "Rate limit exceeded", errResp.Message)
+	assert.Equal(t, Rate{Limit: 1000, Remaining: 0, Reset: *NewTimestamp(1433980800)}, errResp.Rate)
+	assert.Equal(t, "/lookup/rrset/name/fsi.io", errResp.Request.URL.Path)
+	assert.Equal(t, "dnsdb: GET /lookup/rrset/name/fsi.io: 429 Rate limit exceeded", err.Error())
+	assert.True(t, IsQuotaExceeded(err))
+	assert.False(t, IsUnauthorized(err))
+	assert.False(t, IsNoResults(err))
+
+	// Verify that the path in the message stays escaped so it is unambiguous
+	_, _, err = c.RRSet.LookupName(context.Background(), "a/b?c#d e.com", &RRSetLookupNameOptions{Bailiwick: "com"})
+	assert.Equal(t, "dnsdb: GET /lookup/rrset/name/a%2Fb%3Fc%23d%20e.com/ANY/com: 429 Rate limit exceeded", err.Error())
+
+	// Verify that an authorization error is decoded
+	status, body = 403, "Error: API key not authorized\n"
+	_, _, err = c.RRSet.LookupName(context.Background(), "fsi.io", nil)
+	assert.True(t, IsUnauthorized(err))
+	assert.False(t, IsQuotaExceeded(err))
+
+	// Verify that a no results error is an empty result by default
+	status, body = 404, "Error: no results found for query.\n"
+	actual, _, err := c.RRSet.LookupName(context.Background(), "fsi.io", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, []RRSet{}, actual)
+
+	// Verify that a no results error is decoded when opted in
+	c.NoResultsAsError = true
+	_, _, err = c.RRSet.LookupName(context.Background(), "fsi.io", nil)
+	assert.True(t, IsNoResults(err))
+
+	// Verify that other not found errors are not no results
+	status, body = 404, "404 page not found"
+	_, _, err = c.RRSet.LookupName(context.Background(), "fsi.io", nil)
+	assert.False(t, IsNoResults(err))
+
+	// Verify that a JSON error is decoded
+	status, body = 400, `{"error":"Invalid rrtype"}`
+	_, _, err = c.RRSet.LookupName(context.Background(), "fsi.io", nil)
+	assert.True(t, errors.As(err, &errResp))
+	assert.Equal(t, "Invalid rrtype", errResp.Message)
+
+	// Verify that an empty body uses the status text
+	status, body = 500, ""
+	_, _, err = c.RRSet.LookupName(context.Background(), "fsi.io", nil)
+	assert.True(t, errors.As(err, &errResp))
+	assert.Equal(t, "Internal Server Error", errResp.Message)
+}
@@ -0,0 +1,177 @@
+package dnsdb
+
+// Imports
+import (
+	"fmt"
+	"iter"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TimeFence restricts results to a window of time with the same semantics as dnsdbq.
+// A loose fence (the default) matches any record whose lifetime overlaps the window,
+// a strict ("complete") fence only matches records whose lifetime is entirely inside it.
+// A zero After or Before leaves that side of the window open.
+type TimeFence struct {
+	After  time.Time
+	Before time.Time
+	Strict bool
+}
+
+// timeLayouts are the absolute time formats accepted by ParseTime
+var timeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+}
+
+// timeUnits are the suffixes of relative times accepted by ParseTime
+var timeUnits = map[byte]time.Duration{
+	'w': 7 * 24 * time.Hour,
+	'd': 24 * time.Hour,
+	'h': time.Hour,
+	'm': time.Minute,
+	's': time.Second,
+}
+
+// ParseTime parses an absolute or relative time as accepted by dnsdbq.
+// Absolute times are unix timestamps or dates such as "2015-01-02" and "2015-01-02 15:04:05" (UTC).
+// Relative times are negative seconds such as "-86400" or durations such as "-7d" or "1w2d3h", and are relative to now.
+func ParseTime(s string, now time.Time) (time.Time, error) {
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		if i < 0 {
+			return now.Add(time.Duration(i) * time.Second), nil
+		}
+		return time.Unix(i, 0), nil
+	}
+	for _, layout := range timeLayouts {
+		if t, err := time.ParseInLocation(layout, s, time.UTC); err == nil {
+			return t, nil
+		}
+	}
+	d, err := parseRelative(strings.TrimPrefix(s, "-"))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("dnsdb: invalid time %q", s)
+	}
+	return now.Add(-d), nil
+}
+
+// parseRelative parses a duration made of number and unit pairs such as "1w2d"
+func parseRelative(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, strconv.ErrSyntax
+	}
+	var total time.Duration
+	for s != "" {
+		i := 0
+		for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+			i++
+		}
+		if i == 0 || i == len(s) {
+			return 0, strconv.ErrSyntax
+		}
+		unit, ok := timeUnits[s[i]]
+		if !ok {
+			return 0, strconv.ErrSyntax
+		}
+		n, err := strconv.ParseInt(s[:i], 10, 64)
+		if err != nil {
+			return 0, err
+		}
+		total += time.Duration(n) * unit
+		s = s[i+1:]
+	}
+	return total, nil
+}
+
+// NewTimeFence parses after and before with ParseTime relative to the current time, either may be empty
+func NewTimeFence(after, before string, strict bool) (TimeFence, error) {
+	f := TimeFence{Strict: strict}
+	now := time.Now()
+	var err error
+	if after != "" {
+		if f.After, err = ParseTime(after, now); err != nil {
+			return f, err
+		}
+	}
+	if before != "" {
+		if f.Before, err = ParseTime(before, now); err != nil {
+			return f, err
+		}
+	}
+	return f, nil
+}
+
+// Apply returns a copy of opt with the time_first_* and time_last_* parameters for the fence
+func (f TimeFence) Apply(opt LookupOptions) LookupOptions {
+	if f.Strict {
+		opt.TimeFirstAfter = f.After
+		opt.TimeLastBefore = f.Before
+	} else {
+		opt.TimeLastAfter = f.After
+		opt.TimeFirstBefore = f.Before
+	}
+	return opt
+}
+
+// Contains reports if a record first and last seen at the provided times matches the fence
+func (f TimeFence) Contains(first, last time.Time) bool {
+	if f.Strict {
+		return (f.After.IsZero() || !first.Before(f.After)) && (f.Before.IsZero() || !last.After(f.Before))
+	}
+	return (f.After.IsZero() || !last.Before(f.After)) && (f.Before.IsZero() || !first.After(f.Before))
+}
+
+// span returns the widest lifetime covered by the sensor and zone file times of a record
+func span(timeFirst, timeLast, zoneTimeFirst, zoneTimeLast *Timestamp) (first, last time.Time, ok bool) {
+	for _, t := range []*Timestamp{timeFirst, zoneTimeFirst} {
+		if t != nil && (first.IsZero() || t.Before(first)) {
+			first = t.Time
+		}
+	}
+	for _, t := range []*Timestamp{timeLast, zoneTimeLast} {
+		if t != nil && t.After(last) {
+			last = t.Time
+		}
+	}
+	return first, last, !first.IsZero() && !last.IsZero()
+}
+
+// MatchRRSet reports if the record matches the fence, considering the zone_time_* fields the server does not fence on.
+// Records without any times are assumed to match.
+func (f TimeFence) MatchRRSet(r RRSet) bool {
+	first, last, ok := span(r.TimeFirst, r.TimeLast, r.ZoneTimeFirst, r.ZoneTimeLast)
+	return !ok || f.Contains(first, last)
+}
+
+// MatchRData reports if the record matches the fence, see MatchRRSet
+func (f TimeFence) MatchRData(r RData) bool {
+	first, last, ok := span(r.TimeFirst, r.TimeLast, r.ZoneTimeFirst, r.ZoneTimeLast)
+	return !ok || f.Contains(first, last)
+}
+
+// filter is a helper function that drops the values of a stream which do not match
+func filter[T any](seq iter.Seq2[T, error], match func(T) bool) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for r, err := range seq {
+			if err == nil && !match(r) {
+				continue
+			}
+			if !yield(r, err) {
+				return
+			}
+		}
+	}
+}
+
+// FilterRRSet drops the records of a stream which do not match the fence
+func (f TimeFence) FilterRRSet(seq iter.Seq2[RRSet, error]) iter.Seq2[RRSet, error] {
+	return filter(seq, f.MatchRRSet)
+}
+
+// FilterRData drops the records of a stream which do not match the fence
+func (f TimeFence) FilterRData(seq iter.Seq2[RData, error]) iter.Seq2[RData, error] {
+	return filter(seq, f.MatchRData)
+}
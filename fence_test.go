@@ -0,0 +1,85 @@
+package dnsdb
+
+import (
+	"github.com/stretchr/testify/assert"
+
+	"testing"
+	"time"
+)
+
+func Test_ParseTime(t *testing.T) {
+	now := time.Unix(1468324876, 0)
+	for input, expected := range map[string]time.Time{
+		"1372688083":           time.Unix(1372688083, 0),
+		"-86400":               now.Add(-24 * time.Hour),
+		"-7d":                  now.Add(-7 * 24 * time.Hour),
+		"1w2d3h4m5s":           now.Add(-(9*24*time.Hour + 3*time.Hour + 4*time.Minute + 5*time.Second)),
+		"2013-07-01":           time.Date(2013, 7, 1, 0, 0, 0, 0, time.UTC),
+		"2013-07-01 14:14:43":  time.Date(2013, 7, 1, 14, 14, 43, 0, time.UTC),
+		"2013-07-01T14:14:43Z": time.Date(2013, 7, 1, 14, 14, 43, 0, time.UTC),
+	} {
+		actual, err := ParseTime(input, now)
+		assert.Nil(t, err, input)
+		assert.True(t, expected.Equal(actual), input)
+	}
+	for _, input := range []string{"", "-", "yesterday", "-7x", "d7"} {
+		_, err := ParseTime(input, now)
+		assert.NotNil(t, err, input)
+	}
+}
+
+func Test_TimeFence_Apply(t *testing.T) {
+	after := time.Unix(1372688083, 0)
+	before := time.Unix(1468324876, 0)
+
+	// Verify that a loose fence matches any overlap
+	opt := TimeFence{After: after, Before: before}.Apply(LookupOptions{Limit: 10})
+	assert.Equal(t, LookupOptions{Limit: 10, TimeLastAfter: after, TimeFirstBefore: before}, opt)
+
+	// Verify that a strict fence requires containment
+	opt = TimeFence{After: after, Before: before, Strict: true}.Apply(LookupOptions{Limit: 10})
+	assert.Equal(t, LookupOptions{Limit: 10, TimeFirstAfter: after, TimeLastBefore: before}, opt)
+}
+
+func Test_TimeFence_MatchRRSet(t *testing.T) {
+	loose := TimeFence{After: time.Unix(200, 0), Before: time.Unix(300, 0)}
+	strict := TimeFence{After: time.Unix(200, 0), Before: time.Unix(300, 0), Strict: true}
+
+	// Overlapping the start of the window
+	r := RRSet{TimeFirst: NewTimestamp(100), TimeLast: NewTimestamp(250)}
+	assert.True(t, loose.MatchRRSet(r))
+	assert.False(t, strict.MatchRRSet(r))
+
+	// Entirely inside the window, only seen in zone files
+	r = RRSet{ZoneTimeFirst: NewTimestamp(210), ZoneTimeLast: NewTimestamp(290)}
+	assert.True(t, loose.MatchRRSet(r))
+	assert.True(t, strict.MatchRRSet(r))
+
+	// Entirely after the window
+	r = RRSet{TimeFirst: NewTimestamp(400), TimeLast: NewTimestamp(500)}
+	assert.False(t, loose.MatchRRSet(r))
+	assert.False(t, strict.MatchRRSet(r))
+
+	// Zone file times widen the sensor times
+	r = RRSet{TimeFirst: NewTimestamp(210), TimeLast: NewTimestamp(290), ZoneTimeFirst: NewTimestamp(100), ZoneTimeLast: NewTimestamp(290)}
+	assert.True(t, loose.MatchRRSet(r))
+	assert.False(t, strict.MatchRRSet(r))
+
+	// Verify that filtering a stream drops non-matching records
+	seq := func(yield func(RData, error) bool) {
+		for _, r := range []RData{
+			RData{RRName: String("a."), TimeFirst: NewTimestamp(210), TimeLast: NewTimestamp(290)},
+			RData{RRName: String("b."), TimeFirst: NewTimestamp(100), TimeLast: NewTimestamp(290)},
+			RData{RRName: String("c."), ZoneTimeFirst: NewTimestamp(220), ZoneTimeLast: NewTimestamp(230)},
+		} {
+			if !yield(r, nil) {
+				return
+			}
+		}
+	}
+	result, err := collect(strict.FilterRData(seq))
+	assert.Nil(t, err)
+	assert.Len(t, result, 2)
+	assert.Equal(t, "a.", *result[0].RRName)
+	assert.Equal(t, "c.", *result[1].RRName)
+}
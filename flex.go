@@ -0,0 +1,145 @@
+package dnsdb
+
+// Imports
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"iter"
+)
+
+// FlexRRName is a result of a flex search over rrnames as described at https://docs.dnsdb.info/dnsdb-flex/
+type FlexRRName struct {
+	RRName *string `json:"rrname"`
+	RRType *string `json:"rrtype"`
+}
+
+// Lookup fetches the RRSet records that produced this flex result
+func (r FlexRRName) Lookup(ctx context.Context, c *Client, opt *LookupOptions) ([]RRSet, *Response, error) {
+	if r.RRName == nil {
+		return nil, nil, errors.New("dnsdb: flex result has no rrname")
+	}
+	rrOpt := &RRSetLookupNameOptions{}
+	if r.RRType != nil {
+		rrOpt.RRType = RRType(*r.RRType)
+	}
+	if opt != nil {
+		rrOpt.LookupOptions = *opt
+	}
+	return c.RRSet.LookupName(ctx, *r.RRName, rrOpt)
+}
+
+// FlexRData is a result of a flex search over rdata as described at https://docs.dnsdb.info/dnsdb-flex/
+type FlexRData struct {
+	RData    *string `json:"rdata"`
+	RawRData *string `json:"raw_rdata"`
+	RRType   *string `json:"rrtype"`
+}
+
+// Lookup fetches the RData records that produced this flex result
+func (r FlexRData) Lookup(ctx context.Context, c *Client, opt *LookupOptions) ([]RData, *Response, error) {
+	if r.RawRData == nil {
+		return nil, nil, errors.New("dnsdb: flex result has no raw_rdata")
+	}
+	raw, err := hex.DecodeString(*r.RawRData)
+	if err != nil {
+		return nil, nil, err
+	}
+	rdOpt := &RDataLookupRawOptions{}
+	if r.RRType != nil {
+		rdOpt.RRType = RRType(*r.RRType)
+	}
+	if opt != nil {
+		rdOpt.LookupOptions = *opt
+	}
+	return c.RData.LookupRaw(ctx, raw, rdOpt)
+}
+
+// FlexService communicates with the DNSDB Flex search methods of the DNSDB API.
+// Flex is only available in APIv2 so it is always used regardless of the APIVersion of the client.
+type FlexService service
+
+// FlexOptions specifies the optional parameters to the FlexService methods
+type FlexOptions struct {
+	RRType RRType `url:"-"`
+
+	// Exclude omits results matching a second pattern, written in the same syntax as the search.
+	Exclude string `url:"exclude,omitempty"`
+
+	LookupOptions
+}
+
+// path returns the path of the flex search and the options to encode as its query string, or an error if they are invalid
+func (opt *FlexOptions) path(method, key, value string) (string, FlexOptions, error) {
+	if value == "" {
+		return "", FlexOptions{}, &ValidationError{Field: "pattern", Value: value, Reason: "empty"}
+	}
+	segments := []string{method, key, value}
+	var flexOpt FlexOptions
+	if opt != nil {
+		flexOpt = *opt
+		if opt.RRType != "" {
+			if err := opt.RRType.Validate(); err != nil {
+				return "", flexOpt, err
+			}
+			segments = append(segments, string(opt.RRType))
+		}
+	}
+	return joinPath(segments...), flexOpt, nil
+}
+
+// StreamRegexRRNames fetches all rrnames matching the provided regular expression
+func (s *FlexService) StreamRegexRRNames(ctx context.Context, pattern string, opt *FlexOptions) (iter.Seq2[FlexRRName, error], *Response, error) {
+	path, flexOpt, err := opt.path("regex", "rrnames", pattern)
+	if err != nil {
+		return nil, nil, err
+	}
+	return streamVersion[FlexRRName](ctx, s.client, APIv2, path, flexOpt)
+}
+
+// RegexRRNames fetches all rrnames matching the provided regular expression
+func (s *FlexService) RegexRRNames(ctx context.Context, pattern string, opt *FlexOptions) ([]FlexRRName, *Response, error) {
+	return collectLookup(s.StreamRegexRRNames(ctx, pattern, opt))
+}
+
+// StreamGlobRRNames fetches all rrnames matching the provided glob
+func (s *FlexService) StreamGlobRRNames(ctx context.Context, pattern string, opt *FlexOptions) (iter.Seq2[FlexRRName, error], *Response, error) {
+	path, flexOpt, err := opt.path("glob", "rrnames", pattern)
+	if err != nil {
+		return nil, nil, err
+	}
+	return streamVersion[FlexRRName](ctx, s.client, APIv2, path, flexOpt)
+}
+
+// GlobRRNames fetches all rrnames matching the provided glob
+func (s *FlexService) GlobRRNames(ctx context.Context, pattern string, opt *FlexOptions) ([]FlexRRName, *Response, error) {
+	return collectLookup(s.StreamGlobRRNames(ctx, pattern, opt))
+}
+
+// StreamRegexRData fetches all rdata matching the provided regular expression
+func (s *FlexService) StreamRegexRData(ctx context.Context, pattern string, opt *FlexOptions) (iter.Seq2[FlexRData, error], *Response, error) {
+	path, flexOpt, err := opt.path("regex", "rdata", pattern)
+	if err != nil {
+		return nil, nil, err
+	}
+	return streamVersion[FlexRData](ctx, s.client, APIv2, path, flexOpt)
+}
+
+// RegexRData fetches all rdata matching the provided regular expression
+func (s *FlexService) RegexRData(ctx context.Context, pattern string, opt *FlexOptions) ([]FlexRData, *Response, error) {
+	return collectLookup(s.StreamRegexRData(ctx, pattern, opt))
+}
+
+// StreamGlobRData fetches all rdata matching the provided glob
+func (s *FlexService) StreamGlobRData(ctx context.Context, pattern string, opt *FlexOptions) (iter.Seq2[FlexRData, error], *Response, error) {
+	path, flexOpt, err := opt.path("glob", "rdata", pattern)
+	if err != nil {
+		return nil, nil, err
+	}
+	return streamVersion[FlexRData](ctx, s.client, APIv2, path, flexOpt)
+}
+
+// GlobRData fetches all rdata matching the provided glob
+func (s *FlexService) GlobRData(ctx context.Context, pattern string, opt *FlexOptions) ([]FlexRData, *Response, error) {
+	return collectLookup(s.StreamGlobRData(ctx, pattern, opt))
+}
@@ -0,0 +1,95 @@
+package dnsdb
+
+import (
+	"github.com/stretchr/testify/assert"
+
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func Test_FlexService_RegexRRNames(t *testing.T) {
+	// Setup a client, flex is always APIv2
+	c := NewClient(nil)
+
+	// Verify that an error response fails
+	errorServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "Oh No", 500)
+	}))
+	defer errorServer.Close()
+	u, err := url.Parse(errorServer.URL)
+	assert.Nil(t, err)
+	c.BaseURL = u
+	_, _, err = c.Flex.RegexRRNames(context.Background(), `^www\.`, nil)
+	assert.NotNil(t, err)
+
+	// Verify that it gets and parses a response correctly
+	reportServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/dnsdb/v2/regex/rrnames/%5Ewww%5C.farsight.*%3F$/A", r.URL.EscapedPath())
+		assert.Equal(t, "dev", r.URL.Query().Get("exclude"))
+		io.WriteString(w, `{"cond":"begin"}
+{"obj":{"rrname":"www.farsightsecurity.com.","rrtype":"A"}}
+{"obj":{"rrname":"www.farsightsecurity.net.","rrtype":"A"}}
+{"cond":"succeeded"}
+`)
+	}))
+	defer reportServer.Close()
+	u, err = url.Parse(reportServer.URL)
+	assert.Nil(t, err)
+	c.BaseURL = u
+	actual, _, err := c.Flex.RegexRRNames(context.Background(), `^www\.farsight.*?$`, &FlexOptions{
+		RRType:  "A",
+		Exclude: "dev",
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []FlexRRName{
+		FlexRRName{RRName: String("www.farsightsecurity.com."), RRType: String("A")},
+		FlexRRName{RRName: String("www.farsightsecurity.net."), RRType: String("A")},
+	}, actual)
+}
+
+func Test_FlexService_GlobRData(t *testing.T) {
+	// Setup a client
+	c := NewClient(nil)
+
+	// Verify that it gets and parses a response correctly, then pivots into a raw rdata lookup
+	reportServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/dnsdb/v2/glob/rdata/*.fsi.io./MX":
+			io.WriteString(w, `{"cond":"begin"}
+{"obj":{"rdata":"10 hq.fsi.io.","rrtype":"MX","raw_rdata":"000A0268710366736902696F00"}}
+{"cond":"succeeded"}
+`)
+		case "/lookup/rdata/raw/000a0268710366736902696f00/MX":
+			io.WriteString(w, `{"count":45644,"time_first":1372706073,"time_last":1468330740,"rrname":"fsi.io.","rrtype":"MX","rdata":"10 hq.fsi.io."}`)
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	}))
+	defer reportServer.Close()
+	u, err := url.Parse(reportServer.URL)
+	assert.Nil(t, err)
+	c.BaseURL = u
+	actual, _, err := c.Flex.GlobRData(context.Background(), "*.fsi.io.", &FlexOptions{
+		RRType: "MX",
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []FlexRData{
+		FlexRData{RData: String("10 hq.fsi.io."), RRType: String("MX"), RawRData: String("000A0268710366736902696F00")},
+	}, actual)
+	records, _, err := actual[0].Lookup(context.Background(), c, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, []RData{
+		RData{
+			Count:     Uint64(45644),
+			TimeFirst: NewTimestamp(1372706073),
+			TimeLast:  NewTimestamp(1468330740),
+			RRName:    String("fsi.io."),
+			RRType:    String("MX"),
+			RData:     String("10 hq.fsi.io."),
+		},
+	}, records)
+}
@@ -0,0 +1,126 @@
+package dnsdb
+
+// Imports
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Limiter throttles the requests made by a Client so that goroutines sharing it do not race to burn the quota.
+// It enforces a token bucket, the burst_size and burst_window limits of the server, and the last known remaining quota.
+// The zero value allows every request.
+type Limiter struct {
+	Rate  float64 // Sustained requests per second, unlimited if zero.
+	Burst int     // Size of the token bucket, defaults to 1 if zero.
+
+	BurstSize   int           // Most requests in any BurstWindow, unlimited if zero.
+	BurstWindow time.Duration // Window over which BurstSize is enforced.
+
+	// FailFast returns an error instead of blocking when the known remaining quota is zero.
+	// Without it the request blocks until the quota resets.
+	FailFast bool
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+	recent []time.Time // Start of each request in the current BurstWindow, oldest first
+}
+
+// NewLimiterFromQuota returns a Limiter enforcing the burst limits of the provided quota
+func NewLimiterFromQuota(q *Quota) *Limiter {
+	l := &Limiter{}
+	if q.BurstSize > 0 && q.BurstWindow > 0 {
+		l.BurstSize = q.BurstSize
+		l.BurstWindow = time.Duration(q.BurstWindow) * time.Second
+	}
+	return l
+}
+
+// delay returns how long to wait before a request may start, consuming the request if it may start now
+func (l *Limiter) delay(now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var d time.Duration
+
+	// Token bucket
+	burst := float64(max(l.Burst, 1))
+	if l.Rate > 0 {
+		if l.last.IsZero() {
+			l.tokens = burst
+		} else {
+			l.tokens = min(burst, l.tokens+now.Sub(l.last).Seconds()*l.Rate)
+		}
+		l.last = now
+		if l.tokens < 1 {
+			d = time.Duration((1 - l.tokens) / l.Rate * float64(time.Second))
+		}
+	}
+
+	// Sliding burst window
+	if l.BurstSize > 0 && l.BurstWindow > 0 {
+		i := 0
+		for i < len(l.recent) && !l.recent[i].After(now.Add(-l.BurstWindow)) {
+			i++
+		}
+		l.recent = l.recent[i:]
+		if len(l.recent) >= l.BurstSize {
+			d = max(d, l.recent[0].Add(l.BurstWindow).Sub(now))
+		}
+	}
+
+	if d > 0 {
+		return d
+	}
+	if l.Rate > 0 {
+		l.tokens--
+	}
+	if l.BurstSize > 0 && l.BurstWindow > 0 {
+		l.recent = append(l.recent, now)
+	}
+	return 0
+}
+
+// Wait blocks until a request may be made given the last known rate, or returns an error.
+// ErrQuotaExceeded is returned when the quota is exhausted and FailFast is set or the reset time is unknown.
+func (l *Limiter) Wait(ctx context.Context, rate Rate) error {
+	// A known exhausted quota, Limit is zero when nothing is known and -1 when unlimited
+	if rate.Limit > 0 && rate.Remaining == 0 {
+		until := time.Until(rate.Reset.Time)
+		switch {
+		case rate.Reset.IsZero():
+			return ErrQuotaExceeded
+		case until <= 0:
+			// The quota has already reset
+		case l.FailFast:
+			return ErrQuotaExceeded
+		default:
+			if err := sleep(ctx, until); err != nil {
+				return err
+			}
+		}
+	}
+
+	for {
+		d := l.delay(time.Now())
+		if d == 0 {
+			return nil
+		}
+		if err := sleep(ctx, d); err != nil {
+			return err
+		}
+	}
+}
+
+// sleep waits for d or until ctx is done
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
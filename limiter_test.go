@@ -0,0 +1,133 @@
+package dnsdb
+
+import (
+	"github.com/stretchr/testify/assert"
+
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func Test_Limiter_Wait(t *testing.T) {
+	// Verify that the zero value allows everything
+	var l Limiter
+	for i := 0; i < 100; i++ {
+		assert.Nil(t, l.Wait(context.Background(), Rate{}))
+	}
+
+	// Verify that the token bucket throttles after the burst
+	l = Limiter{Rate: 100, Burst: 2}
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		assert.Nil(t, l.Wait(context.Background(), Rate{}))
+	}
+	assert.True(t, time.Since(start) >= 15*time.Millisecond)
+
+	// Verify that the burst window throttles
+	l = Limiter{BurstSize: 2, BurstWindow: 30 * time.Millisecond}
+	start = time.Now()
+	for i := 0; i < 3; i++ {
+		assert.Nil(t, l.Wait(context.Background(), Rate{}))
+	}
+	assert.True(t, time.Since(start) >= 25*time.Millisecond)
+
+	// Verify that waiting is cut short by the context
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+	l = Limiter{BurstSize: 1, BurstWindow: time.Hour}
+	assert.Nil(t, l.Wait(ctx, Rate{}))
+	assert.Equal(t, context.DeadlineExceeded, l.Wait(ctx, Rate{}))
+
+	// Verify that an exhausted quota fails fast or blocks until the reset
+	l = Limiter{FailFast: true}
+	assert.Equal(t, ErrQuotaExceeded, l.Wait(context.Background(), Rate{Limit: 10, Remaining: 0, Reset: Timestamp{time.Now().Add(time.Hour)}}))
+	assert.Nil(t, l.Wait(context.Background(), Rate{Limit: 10, Remaining: 0, Reset: Timestamp{time.Now().Add(-time.Hour)}}))
+	assert.Nil(t, l.Wait(context.Background(), Rate{Limit: -1, Remaining: 0}))
+	l = Limiter{}
+	assert.Equal(t, ErrQuotaExceeded, l.Wait(context.Background(), Rate{Limit: 10, Remaining: 0}))
+	start = time.Now()
+	assert.Nil(t, l.Wait(context.Background(), Rate{Limit: 10, Remaining: 0, Reset: Timestamp{time.Now().Add(20 * time.Millisecond)}}))
+	assert.True(t, time.Since(start) >= 15*time.Millisecond)
+}
+
+func Test_NewLimiterFromQuota(t *testing.T) {
+	l := NewLimiterFromQuota(&Quota{BurstSize: 10, BurstWindow: 300})
+	assert.Equal(t, 10, l.BurstSize)
+	assert.Equal(t, 5*time.Minute, l.BurstWindow)
+	l = NewLimiterFromQuota(&Quota{BurstSize: -1, BurstWindow: -1})
+	assert.Equal(t, 0, l.BurstSize)
+}
+
+func Test_Client_Rate(t *testing.T) {
+	// Setup a client which fails fast once the quota is exhausted
+	c := NewClient(nil)
+	c.Limiter = &Limiter{FailFast: true}
+
+	// A server which counts down the remaining quota
+	var mu sync.Mutex
+	remaining, requests := 50, 0
+	reset := time.Now().Add(time.Hour).Unix()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/lookup/rate_limit" {
+			io.WriteString(w, `{"rate":{"reset":"n/a","limit":50,"remaining":0}}`)
+			return
+		}
+		mu.Lock()
+		remaining, requests = max(remaining-1, 0), requests+1
+		w.Header().Set("X-RateLimit-Limit", "50")
+		w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(remaining))
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset, 10))
+		mu.Unlock()
+		io.WriteString(w, `{"count":1,"rrname":"fsi.io.","rrtype":"A","rdata":["104.244.13.104"]}`)
+	}))
+	defer server.Close()
+	u, err := url.Parse(server.URL)
+	assert.Nil(t, err)
+	c.BaseURL = u
+
+	// Verify that nothing is known before the first response
+	assert.Equal(t, Rate{}, c.Rate())
+
+	// Verify that the rate is safely shared by several goroutines
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 4; j++ {
+				_, _, err := c.RRSet.LookupName(context.Background(), "fsi.io", nil)
+				assert.Nil(t, err)
+				assert.Equal(t, 50, c.Rate().Limit)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 10, c.Rate().Remaining)
+
+	// Verify that a response which finished late does not replace a later rate
+	c.setRate(Rate{Limit: 50, Remaining: 20, Reset: c.Rate().Reset})
+	assert.Equal(t, 10, c.Rate().Remaining)
+	c.setRate(Rate{Limit: 50, Remaining: 0, Reset: Timestamp{time.Unix(reset-86400, 0)}})
+	assert.Equal(t, 10, c.Rate().Remaining)
+
+	// Verify that once the quota is known to be exhausted no more requests are sent
+	for c.Rate().Remaining > 0 {
+		_, _, err = c.RRSet.LookupName(context.Background(), "fsi.io", nil)
+		assert.Nil(t, err)
+	}
+	assert.Equal(t, 0, c.Rate().Remaining)
+	assert.Equal(t, reset, c.Rate().Reset.Unix())
+	_, _, err = c.RRSet.LookupName(context.Background(), "fsi.io", nil)
+	assert.True(t, IsQuotaExceeded(err))
+	assert.Equal(t, 50, requests)
+
+	// Verify that the quota can still be checked since it does not count against itself
+	_, _, err = c.Account.RateLimit(context.Background())
+	assert.Nil(t, err)
+}
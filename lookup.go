@@ -2,6 +2,7 @@ package dnsdb
 
 // Imports
 import (
+	"context"
 	"net/http"
 	"time"
 
@@ -11,25 +12,44 @@ import (
 // LookupOptions defines the optional parameters to all lookup calls
 type LookupOptions struct {
 	Limit           int64     `url:"limit,omitempty"`
-	TimeFirstBefore time.Time `url:"time_first_before,omitempty"`
-	TimeFirstAfter  time.Time `url:"time_first_after,omitempty"`
-	TimeLastBefore  time.Time `url:"time_last_before,omitempty"`
-	TimeLastAfter   time.Time `url:"time_last_after,omitempty"`
+	Offset          int64     `url:"offset,omitempty"`
+	TimeFirstBefore time.Time `url:"time_first_before,omitempty,unix"`
+	TimeFirstAfter  time.Time `url:"time_first_after,omitempty,unix"`
+	TimeLastBefore  time.Time `url:"time_last_before,omitempty,unix"`
+	TimeLastAfter   time.Time `url:"time_last_after,omitempty,unix"`
+
+	// Aggr set to false returns unaggregated results, one per sensor time window, instead of a single aggregate.
+	Aggr *bool `url:"aggr,omitempty"`
+
+	// HumanTime returns times as strings rather than unix timestamps, Timestamp decodes either.
+	HumanTime bool `url:"humantime,omitempty"`
+
+	// SWClient and Version identify the client software making the request.
+	SWClient string `url:"swclient,omitempty"`
+	Version  string `url:"version,omitempty"`
+
+	// MaxCount stops summarizing once this many records have been counted, it is ignored by lookups.
+	MaxCount int64 `url:"max_count,omitempty"`
 }
 
 // NewLookupRequest is a convienience function that extends NewRequest for Lookup methods
-func (c *Client) NewLookupRequest(method, urlStr string, opt LookupOptions) (*http.Request, error) {
+func (c *Client) NewLookupRequest(ctx context.Context, method, urlStr string, opt LookupOptions) (*http.Request, error) {
+	return c.newLookupRequest(ctx, method, urlStr, opt)
+}
+
+// newLookupRequest is NewLookupRequest for any options which embed LookupOptions, such as FlexOptions
+func (c *Client) newLookupRequest(ctx context.Context, method, urlStr string, opt interface{}) (*http.Request, error) {
 	qs, err := query.Values(opt)
 	if err != nil {
 		return nil, err
 	}
 
-	req, err := c.NewRequest(method, urlStr+"?"+qs.Encode(), nil)
+	req, err := c.NewRequest(ctx, method, urlStr+"?"+qs.Encode(), nil)
 	if err != nil {
 		return nil, err
 	}
 
-	req.Header.Add("Accept", "application/json")
+	req.Header.Add("Accept", c.APIVersion.accept())
 
 	return req, nil
 }
@@ -0,0 +1,50 @@
+package dnsdb
+
+import (
+	"github.com/stretchr/testify/assert"
+
+	"context"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func Test_Client_NewLookupRequest(t *testing.T) {
+	// Setup a client
+	c := NewClient(nil)
+
+	// Verify that no options produces no query
+	req, err := c.NewLookupRequest(context.Background(), "GET", "lookup/rrset/name/fsi.io", LookupOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, "", req.URL.RawQuery)
+	assert.Equal(t, "application/json", req.Header.Get("Accept"))
+
+	// Verify that every option is encoded
+	req, err = c.NewLookupRequest(context.Background(), "GET", "lookup/rrset/name/fsi.io", LookupOptions{
+		Limit:           10,
+		Offset:          20,
+		TimeFirstBefore: time.Unix(1372688083, 0),
+		TimeFirstAfter:  time.Unix(1372688084, 0),
+		TimeLastBefore:  time.Unix(1372688085, 0),
+		TimeLastAfter:   time.Unix(1372688086, 0),
+		Aggr:            Bool(false),
+		HumanTime:       true,
+		SWClient:        "go-dnsdb",
+		Version:         "1.0",
+		MaxCount:        30,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, url.Values{
+		"limit":             []string{"10"},
+		"offset":            []string{"20"},
+		"time_first_before": []string{"1372688083"},
+		"time_first_after":  []string{"1372688084"},
+		"time_last_before":  []string{"1372688085"},
+		"time_last_after":   []string{"1372688086"},
+		"aggr":              []string{"false"},
+		"humantime":         []string{"true"},
+		"swclient":          []string{"go-dnsdb"},
+		"version":           []string{"1.0"},
+		"max_count":         []string{"30"},
+	}, req.URL.Query())
+}
@@ -0,0 +1,133 @@
+package dnsdb
+
+// Imports
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Limits on the presentation form of a domain name, from RFC 1035
+const (
+	maxLabelLength = 63
+	maxNameLength  = 253 // 255 octets on the wire less the length of the first label and the root label
+)
+
+// ValidationError reports a query component which is invalid, it is returned before any request is sent
+type ValidationError struct {
+	Field  string // Which component is invalid, such as "name", "bailiwick" or "rrtype"
+	Value  string // The invalid value
+	Reason string // Why the value is invalid, may be empty
+}
+
+// Error implements the error interface
+func (e *ValidationError) Error() string {
+	if e.Reason == "" {
+		return fmt.Sprintf("dnsdb: invalid %s %q", e.Field, e.Value)
+	}
+	return fmt.Sprintf("dnsdb: invalid %s %q: %s", e.Field, e.Value, e.Reason)
+}
+
+// splitLabels splits a name in presentation form into its labels, keeping escapes such as "\." and "\046" intact.
+// It also returns the length of each label on the wire.
+func splitLabels(name string) (labels []string, lengths []int, err error) {
+	start, length := 0, 0
+	for i := 0; i < len(name); i++ {
+		switch name[i] {
+		case '\\':
+			switch {
+			case i+3 < len(name) && isDigit(name[i+1]) && isDigit(name[i+2]) && isDigit(name[i+3]):
+				if n := int(name[i+1]-'0')*100 + int(name[i+2]-'0')*10 + int(name[i+3]-'0'); n > 255 {
+					return nil, nil, fmt.Errorf("escape \\%s is out of range", name[i+1:i+4])
+				}
+				i += 3
+			case i+1 < len(name):
+				i++
+			default:
+				return nil, nil, fmt.Errorf("trailing backslash")
+			}
+			length++
+		case '.':
+			labels, lengths = append(labels, name[start:i]), append(lengths, length)
+			start, length = i+1, 0
+		default:
+			length++
+		}
+	}
+	return append(labels, name[start:]), append(lengths, length), nil
+}
+
+// normalizeName validates a domain name in presentation form and removes its trailing dot, the root is returned as ".".
+// If wildcard is set the name may have a "*" as its entire leftmost or rightmost label, but not both.
+func normalizeName(field, name string, wildcard bool) (string, error) {
+	invalid := func(reason string) error {
+		return &ValidationError{Field: field, Value: name, Reason: reason}
+	}
+	if name == "" {
+		return "", invalid("empty")
+	}
+	if name == "." {
+		return name, nil
+	}
+	trimmed := name
+	if strings.HasSuffix(trimmed, ".") && !strings.HasSuffix(trimmed, `\.`) {
+		trimmed = trimmed[:len(trimmed)-1]
+	}
+
+	labels, lengths, err := splitLabels(trimmed)
+	if err != nil {
+		return "", invalid(err.Error())
+	}
+	total := len(labels) - 1 // The dots between labels
+	for i, label := range labels {
+		total += lengths[i]
+		switch {
+		case label == "":
+			return "", invalid("empty label")
+		case lengths[i] > maxLabelLength:
+			return "", invalid(fmt.Sprintf("label %q is longer than %d octets", label, maxLabelLength))
+		case label == "*":
+			if !wildcard {
+				return "", invalid("wildcards are not allowed")
+			}
+			if len(labels) == 1 {
+				return "", invalid("a wildcard needs at least one other label")
+			}
+			if i != 0 && i != len(labels)-1 {
+				return "", invalid("wildcards are only supported as the leftmost or rightmost label")
+			}
+		case strings.Contains(label, "*") && !strings.Contains(label, `\*`):
+			return "", invalid("wildcards must be a whole label")
+		}
+	}
+	if wildcard && len(labels) > 1 && labels[0] == "*" && labels[len(labels)-1] == "*" {
+		return "", invalid("wildcards cannot be both leftmost and rightmost")
+	}
+	if total > maxNameLength {
+		return "", invalid(fmt.Sprintf("longer than %d octets", maxNameLength))
+	}
+	return trimmed, nil
+}
+
+// segmentUnescaper restores the characters which url.PathEscape escapes but are valid in a path segment.
+// The API expects commas in the addr,bits form of a network and asterisks in wildcards and globs.
+var segmentUnescaper = strings.NewReplacer("%2C", ",", "%2A", "*")
+
+// escapeSegment percent-escapes a single path segment, including the dot segments which URL resolution would remove
+func escapeSegment(s string) string {
+	switch s {
+	case ".":
+		return "%2E"
+	case "..":
+		return "%2E%2E"
+	}
+	return segmentUnescaper.Replace(url.PathEscape(s))
+}
+
+// joinPath escapes each segment and joins them into a path
+func joinPath(segments ...string) string {
+	for i, seg := range segments {
+		segments[i] = escapeSegment(seg)
+	}
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,116 @@
+package dnsdb
+
+import (
+	"github.com/stretchr/testify/assert"
+
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func Test_normalizeName(t *testing.T) {
+	// Verify that valid names are normalized without a trailing dot
+	for name, expected := range map[string]string{
+		"example.com":                 "example.com",
+		"Example.COM.":                "Example.COM",
+		".":                           ".",
+		"*.example.com":               "*.example.com",
+		"www.example.*":               "www.example.*",
+		`a\.b.example.com.`:           `a\.b.example.com`,
+		`trailing\.`:                  `trailing\.`,
+		`\065\066.example`:            `\065\066.example`,
+		strings.Repeat("a", 63) + ".": strings.Repeat("a", 63),
+	} {
+		actual, err := normalizeName("name", name, true)
+		assert.Nil(t, err, name)
+		assert.Equal(t, expected, actual)
+	}
+
+	// Verify that invalid names fail with a ValidationError
+	for _, name := range []string{
+		"",
+		"example..com",
+		".example.com",
+		strings.Repeat("a", 64) + ".com",
+		strings.Repeat(strings.Repeat("a", 63)+".", 4) + "com",
+		"www.*.example.com",
+		"*.example.*",
+		"*",
+		"w*.example.com",
+		`\256.example`,
+		`example\`,
+	} {
+		_, err := normalizeName("name", name, true)
+		var verr *ValidationError
+		assert.True(t, errors.As(err, &verr), name)
+	}
+
+	// Verify that escapes count as a single octet
+	_, err := normalizeName("name", strings.Repeat(`\097`, 63), false)
+	assert.Nil(t, err)
+
+	// Verify that wildcards can be disallowed
+	_, err = normalizeName("bailiwick", "*.com", false)
+	assert.EqualError(t, err, `dnsdb: invalid bailiwick "*.com": wildcards are not allowed`)
+}
+
+func Test_escapeSegment(t *testing.T) {
+	assert.Equal(t, "a%2Fb%3Fc%23d%20e", escapeSegment("a/b?c#d e"))
+	assert.Equal(t, "%2E", escapeSegment("."))
+	assert.Equal(t, "192.0.2.0,24", escapeSegment("192.0.2.0,24"))
+	assert.Equal(t, "*.example.com", escapeSegment("*.example.com"))
+}
+
+func Test_Path_Escaping(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.EscapedPath())
+		io.WriteString(w, `{"count":1}`)
+	}))
+	defer server.Close()
+	c := NewClient(nil)
+	u, err := url.Parse(server.URL + "/")
+	assert.Nil(t, err)
+	c.BaseURL = u
+	ctx := context.Background()
+
+	// Verify that every segment is escaped
+	_, _, err = c.RRSet.LookupName(ctx, "a/b?c#d e.example.com.", &RRSetLookupNameOptions{Bailiwick: "example.com."})
+	assert.Nil(t, err)
+	_, _, err = c.RRSet.LookupName(ctx, ".", &RRSetLookupNameOptions{RRType: RRTypeNS})
+	assert.Nil(t, err)
+	_, _, err = c.RData.LookupName(ctx, "*.example.com", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		"/lookup/rrset/name/a%2Fb%3Fc%23d%20e.example.com/ANY/example.com",
+		"/lookup/rrset/name/%2E/NS",
+		"/lookup/rdata/name/*.example.com",
+	}, paths)
+
+	// Verify that invalid components fail before any request is sent
+	var verr *ValidationError
+	_, _, err = c.RRSet.LookupName(ctx, "www.*.example.com", nil)
+	assert.True(t, errors.As(err, &verr))
+	assert.Equal(t, "name", verr.Field)
+	_, _, err = c.RRSet.SummarizeName(ctx, "example.com", &RRSetLookupNameOptions{RRType: "A", Bailiwick: "a..b"})
+	assert.True(t, errors.As(err, &verr))
+	assert.Equal(t, "bailiwick", verr.Field)
+	_, _, err = c.RData.LookupName(ctx, "", nil)
+	assert.True(t, errors.As(err, &verr))
+	_, _, err = c.RData.LookupIP(ctx, nil, nil)
+	assert.True(t, errors.As(err, &verr))
+	_, _, err = c.RData.LookupIPNet(ctx, net.IPNet{}, nil)
+	assert.True(t, errors.As(err, &verr))
+	_, _, err = c.RData.LookupRaw(ctx, nil, nil)
+	assert.True(t, errors.As(err, &verr))
+	_, _, err = c.RData.LookupName(ctx, "example.com", &RDataLookupNameOptions{RRType: "BOGUS"})
+	assert.True(t, errors.As(err, &verr))
+	assert.Equal(t, "rrtype", verr.Field)
+	assert.Len(t, paths, 3)
+}
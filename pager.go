@@ -0,0 +1,62 @@
+package dnsdb
+
+// Imports
+import (
+	"context"
+	"errors"
+	"iter"
+)
+
+// ErrOffsetMax is returned by a pager when more results remain beyond the offset_max of the quota
+var ErrOffsetMax = errors.New("dnsdb: results exceed offset_max")
+
+// paginate is a helper function that repeatedly calls fetch with increasing offsets until the results are complete.
+// The Limit of opt is the page size, it defaults to and is capped at results_max of the quota, since a page cut short
+// by the server would otherwise look like the last one. No page starts beyond offset_max.
+func paginate[T any](ctx context.Context, c *Client, opt LookupOptions, fetch func(LookupOptions) (iter.Seq2[T, error], *Response, error)) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		quota, _, err := c.Account.RateLimit(ctx)
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+		if resultsMax := int64(quota.ResultsMax); resultsMax > 0 && (opt.Limit <= 0 || opt.Limit > resultsMax) {
+			opt.Limit = resultsMax
+		}
+		offsetMax := int64(quota.OffsetMax)
+
+		// With NoResultsAsError an empty page after the first means the results ended on a page boundary
+		for first := true; ; first = false {
+			seq, resp, err := fetch(opt)
+			if err != nil {
+				if !first && errors.Is(err, ErrNoResults) {
+					return
+				}
+				yield(zero, err)
+				return
+			}
+			var count int64
+			for r, err := range seq {
+				if !first && count == 0 && errors.Is(err, ErrNoResults) {
+					return
+				}
+				if !yield(r, err) || err != nil {
+					return
+				}
+				count++
+			}
+
+			// APIv2 tells us when the results are complete, in APIv1 a short page is the last.
+			// Without a known page size there is no way to tell so a single page is all we get.
+			if resp.Condition == ConditionSucceeded || count == 0 || opt.Limit <= 0 || (resp.Condition == "" && count < opt.Limit) {
+				return
+			}
+			opt.Offset += count
+			if offsetMax >= 0 && opt.Offset > offsetMax {
+				yield(zero, ErrOffsetMax)
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package dnsdb
+
+import (
+	"github.com/stretchr/testify/assert"
+
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+)
+
+func Test_RRSetService_PaginateName(t *testing.T) {
+	// Setup a client
+	c := NewClient(nil)
+
+	// A server with 5 records, a results_max of 2 and a configurable offset_max
+	offsetMax := `"n/a"`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/lookup/rate_limit" {
+			io.WriteString(w, `{"rate":{"reset":1433980800,"limit":1000,"remaining":999,"results_max":2,"offset_max":`+offsetMax+`}}`)
+			return
+		}
+		assert.Equal(t, "2", r.URL.Query().Get("limit"))
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		for i := offset; i < 5 && i < offset+2; i++ {
+			fmt.Fprintf(w, `{"count":%d,"rrname":"farsightsecurity.com.","rrtype":"A","rdata":["104.244.13.104"]}`+"\n", i)
+		}
+	}))
+	defer server.Close()
+	u, err := url.Parse(server.URL)
+	assert.Nil(t, err)
+	c.BaseURL = u
+
+	// Verify that every page is walked
+	var counts []uint64
+	for r, err := range c.RRSet.PaginateName(context.Background(), "farsightsecurity.com", nil) {
+		assert.Nil(t, err)
+		counts = append(counts, *r.Count)
+	}
+	assert.Equal(t, []uint64{0, 1, 2, 3, 4}, counts)
+
+	// Verify that offset_max is respected
+	offsetMax = `"2"`
+	counts = nil
+	var lastErr error
+	for r, err := range c.RRSet.PaginateName(context.Background(), "farsightsecurity.com", nil) {
+		if err != nil {
+			lastErr = err
+			break
+		}
+		counts = append(counts, *r.Count)
+	}
+	assert.Equal(t, []uint64{0, 1, 2, 3}, counts)
+	assert.Equal(t, ErrOffsetMax, lastErr)
+
+	// Verify that a limit above results_max is capped rather than ending after the first page
+	offsetMax = `"n/a"`
+	counts = nil
+	for r, err := range c.RRSet.PaginateName(context.Background(), "farsightsecurity.com", &RRSetLookupNameOptions{
+		LookupOptions: LookupOptions{Limit: 10},
+	}) {
+		assert.Nil(t, err)
+		counts = append(counts, *r.Count)
+	}
+	assert.Equal(t, []uint64{0, 1, 2, 3, 4}, counts)
+}
+
+func Test_RDataService_PaginateName(t *testing.T) {
+	// Setup a client speaking APIv2
+	c := NewClient(nil)
+	c.APIVersion = APIv2
+
+	// A server which reports when the results are complete
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/dnsdb/v2/rate_limit" {
+			io.WriteString(w, `{"rate":{"reset":1433980800,"limit":1000,"remaining":999,"results_max":100,"offset_max":1000}}`)
+			return
+		}
+		assert.Equal(t, "2", r.URL.Query().Get("limit"))
+		io.WriteString(w, `{"cond":"begin"}`+"\n")
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		for i := offset; i < 4 && i < offset+2; i++ {
+			fmt.Fprintf(w, `{"obj":{"count":%d,"rrname":"fsi.io.","rrtype":"MX","rdata":"10 hq.fsi.io."}}`+"\n", i)
+		}
+		if offset+2 >= 4 {
+			io.WriteString(w, `{"cond":"succeeded"}`+"\n")
+		} else {
+			io.WriteString(w, `{"cond":"limited","msg":"Result limit reached"}`+"\n")
+		}
+	}))
+	defer server.Close()
+	u, err := url.Parse(server.URL)
+	assert.Nil(t, err)
+	c.BaseURL = u
+
+	var counts []uint64
+	for r, err := range c.RData.PaginateName(context.Background(), "hq.fsi.io", &RDataLookupNameOptions{
+		LookupOptions: LookupOptions{Limit: 2},
+	}) {
+		assert.Nil(t, err)
+		counts = append(counts, *r.Count)
+	}
+	assert.Equal(t, []uint64{0, 1, 2, 3}, counts)
+}
@@ -0,0 +1,448 @@
+package dnsdb
+
+// Imports
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"iter"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/go-querystring/query"
+)
+
+// QueryMode selects which part of the DNSDB API a Query is sent to
+type QueryMode string
+
+const (
+	QueryRRSet QueryMode = "rrset" // RRSetService lookups by owner name, or flex searches over rrnames
+	QueryRData QueryMode = "rdata" // RDataService lookups by name, IP or raw rdata, or flex searches over rdata
+)
+
+// QueryKind selects what the Value of a Query is
+type QueryKind string
+
+const (
+	QueryName  QueryKind = "name"  // An owner name for rrset queries or a name within the rdata for rdata queries
+	QueryIP    QueryKind = "ip"    // An IP address, or a network in the addr,bits or addr/bits form, for rdata queries
+	QueryRaw   QueryKind = "raw"   // Hex encoded rdata for rdata queries
+	QueryRegex QueryKind = "regex" // A FlexService regular expression
+	QueryGlob  QueryKind = "glob"  // A FlexService glob
+)
+
+// flex reports if the kind is a FlexService search
+func (k QueryKind) flex() bool {
+	return k == QueryRegex || k == QueryGlob
+}
+
+// Query is a single lookup, summarize or flex search described as a value, so that it can be logged, stored,
+// compared and replayed. It renders to the API path with String and is parsed back by ParseQueryURL,
+// ParseBatchLine parses the dnsdbq syntax and it marshals to JSON using the API parameter names.
+type Query struct {
+	Mode      QueryMode
+	Kind      QueryKind
+	Value     string
+	RRType    RRType
+	Bailiwick string // Only used by rrset queries
+	Exclude   string // Only used by flex searches, see FlexOptions
+	Summarize bool   // Summarize instead of lookup, not supported by flex searches
+
+	LookupOptions
+}
+
+// ipValue parses the Value of an ip query, ipnet is nil if it is a single address
+func (q Query) ipValue() (ip net.IP, ipnet *net.IPNet, err error) {
+	if strings.ContainsAny(q.Value, ",/") {
+		_, ipnet, err = net.ParseCIDR(strings.Replace(q.Value, ",", "/", 1))
+		return nil, ipnet, err
+	}
+	if ip = net.ParseIP(q.Value); ip == nil {
+		return nil, nil, fmt.Errorf("dnsdb: invalid IP address %q", q.Value)
+	}
+	return ip, nil, nil
+}
+
+// rawValue decodes the Value of a raw query
+func (q Query) rawValue() ([]byte, error) {
+	raw, err := hex.DecodeString(q.Value)
+	if err != nil {
+		return nil, fmt.Errorf("dnsdb: invalid raw rdata %q: %w", q.Value, err)
+	}
+	return raw, nil
+}
+
+// path returns the path of the query without any APIVersion prefix and the options to encode as its query string,
+// or an error if they are invalid
+func (q Query) path() (string, interface{}, error) {
+	if q.Kind.flex() {
+		if q.Summarize {
+			return "", q.LookupOptions, fmt.Errorf("dnsdb: flex searches cannot be summarized")
+		}
+		key := "rdata"
+		if q.Mode == QueryRRSet {
+			key = "rrnames"
+		} else if q.Mode != QueryRData {
+			return "", q.LookupOptions, fmt.Errorf("dnsdb: unsupported query %s/%s", q.Mode, q.Kind)
+		}
+		opt := &FlexOptions{RRType: q.RRType, Exclude: q.Exclude, LookupOptions: q.LookupOptions}
+		return opt.path(string(q.Kind), key, q.Value)
+	}
+
+	var path string
+	var opt LookupOptions
+	var err error
+	switch {
+	case q.Mode == QueryRRSet && q.Kind == QueryName:
+		path, opt, err = (&RRSetLookupNameOptions{RRType: q.RRType, Bailiwick: q.Bailiwick, LookupOptions: q.LookupOptions}).path(q.Value)
+	case q.Mode != QueryRData:
+		err = fmt.Errorf("dnsdb: unsupported query %s/%s", q.Mode, q.Kind)
+	case q.Kind == QueryName:
+		path, opt, err = (&RDataLookupNameOptions{RRType: q.RRType, LookupOptions: q.LookupOptions}).path(q.Value)
+	case q.Kind == QueryIP:
+		var ip net.IP
+		var ipnet *net.IPNet
+		if ip, ipnet, err = q.ipValue(); err != nil {
+			break
+		}
+		if ipnet != nil {
+			path, opt, err = (&RDataLookupIPNetOptions{RRType: q.RRType, LookupOptions: q.LookupOptions}).path(*ipnet)
+		} else {
+			path, opt, err = (&RDataLookupIPOptions{RRType: q.RRType, LookupOptions: q.LookupOptions}).path(ip)
+		}
+	case q.Kind == QueryRaw:
+		var raw []byte
+		if raw, err = q.rawValue(); err != nil {
+			break
+		}
+		path, opt, err = (&RDataLookupRawOptions{RRType: q.RRType, LookupOptions: q.LookupOptions}).path(raw)
+	default:
+		err = fmt.Errorf("dnsdb: unsupported query %s/%s", q.Mode, q.Kind)
+	}
+	if err != nil {
+		return "", opt, err
+	}
+	if q.Summarize {
+		return "summarize/" + path, opt, nil
+	}
+	return "lookup/" + path, opt, nil
+}
+
+// String returns the path and query string of the query below the API base URL, such as
+// "lookup/rrset/name/example.com/A?limit=10", or the error if the query is invalid
+func (q Query) String() string {
+	path, opt, err := q.path()
+	if err != nil {
+		return err.Error()
+	}
+	qs, err := query.Values(opt)
+	if err != nil {
+		return err.Error()
+	}
+	if encoded := qs.Encode(); encoded != "" {
+		return path + "?" + encoded
+	}
+	return path
+}
+
+// NewQueryRequest creates an API request for the query, flex searches always use APIv2
+func (c *Client) NewQueryRequest(ctx context.Context, q Query) (*http.Request, error) {
+	path, opt, err := q.path()
+	if err != nil {
+		return nil, err
+	}
+	version := c.APIVersion
+	if q.Kind.flex() {
+		version = APIv2
+	}
+	if version == APIv2 {
+		path = v2Prefix + path
+	}
+	req, err := c.newLookupRequest(ctx, "GET", path, opt)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", version.accept())
+	return req, nil
+}
+
+// ParseQueryURL parses an API URL, or a path relative to the API such as the String of a Query, back into a Query.
+// Any base path and the APIv2 prefix before the lookup, summarize, regex or glob segment are ignored.
+func ParseQueryURL(s string) (Query, error) {
+	var q Query
+	u, err := url.Parse(s)
+	if err != nil {
+		return q, err
+	}
+	segments := strings.Split(strings.TrimPrefix(u.EscapedPath(), "/"), "/")
+	for i, seg := range segments {
+		if segments[i], err = url.PathUnescape(seg); err != nil {
+			return q, err
+		}
+	}
+	start := -1
+	for i, seg := range segments {
+		if seg == "lookup" || seg == "summarize" || seg == "regex" || seg == "glob" {
+			start = i
+			break
+		}
+	}
+	if start < 0 {
+		return q, fmt.Errorf("dnsdb: not a query URL %q", s)
+	}
+	method, segments := segments[start], segments[start+1:]
+
+	invalid := fmt.Errorf("dnsdb: invalid query URL %q", s)
+	switch method {
+	case "regex", "glob":
+		if len(segments) < 2 || len(segments) > 3 {
+			return q, invalid
+		}
+		q.Kind, q.Value = QueryKind(method), segments[1]
+		switch segments[0] {
+		case "rrnames":
+			q.Mode = QueryRRSet
+		case "rdata":
+			q.Mode = QueryRData
+		default:
+			return q, invalid
+		}
+		if len(segments) == 3 {
+			q.RRType = RRType(segments[2])
+		}
+	default:
+		if len(segments) < 3 {
+			return q, invalid
+		}
+		q.Summarize = method == "summarize"
+		q.Mode, q.Kind, q.Value = QueryMode(segments[0]), QueryKind(segments[1]), segments[2]
+		max := 4
+		switch {
+		case q.Mode == QueryRRSet && q.Kind == QueryName:
+			max = 5
+		case q.Mode == QueryRData && (q.Kind == QueryName || q.Kind == QueryIP || q.Kind == QueryRaw):
+		default:
+			return q, invalid
+		}
+		if len(segments) > max {
+			return q, invalid
+		}
+		if len(segments) >= 4 {
+			q.RRType = RRType(segments[3])
+		}
+		if len(segments) == 5 {
+			q.Bailiwick = segments[4]
+		}
+	}
+	if q.RRType != "" {
+		if err := q.RRType.Validate(); err != nil {
+			return q, err
+		}
+	}
+	values := u.Query()
+	if q.Kind.flex() {
+		q.Exclude = values.Get("exclude")
+		values.Del("exclude")
+	}
+	q.LookupOptions, err = parseLookupValues(values)
+	return q, err
+}
+
+// parseLookupValues is the inverse of encoding LookupOptions as a query string
+func parseLookupValues(values url.Values) (LookupOptions, error) {
+	var opt LookupOptions
+	times := map[string]*time.Time{
+		"time_first_before": &opt.TimeFirstBefore,
+		"time_first_after":  &opt.TimeFirstAfter,
+		"time_last_before":  &opt.TimeLastBefore,
+		"time_last_after":   &opt.TimeLastAfter,
+	}
+	for key := range values {
+		v := values.Get(key)
+		var err error
+		switch key {
+		case "limit":
+			opt.Limit, err = strconv.ParseInt(v, 10, 64)
+		case "offset":
+			opt.Offset, err = strconv.ParseInt(v, 10, 64)
+		case "max_count":
+			opt.MaxCount, err = strconv.ParseInt(v, 10, 64)
+		case "time_first_before", "time_first_after", "time_last_before", "time_last_after":
+			var secs int64
+			if secs, err = strconv.ParseInt(v, 10, 64); err == nil {
+				*times[key] = time.Unix(secs, 0)
+			}
+		case "aggr":
+			var b bool
+			b, err = strconv.ParseBool(v)
+			opt.Aggr = Bool(b)
+		case "humantime":
+			opt.HumanTime, err = strconv.ParseBool(v)
+		case "swclient":
+			opt.SWClient = v
+		case "version":
+			opt.Version = v
+		default:
+			err = fmt.Errorf("unknown parameter")
+		}
+		if err != nil {
+			return opt, fmt.Errorf("dnsdb: invalid query parameter %s=%q: %w", key, v, err)
+		}
+	}
+	return opt, nil
+}
+
+// queryJSON is the JSON form of a Query, the options use the API parameter names and values
+type queryJSON struct {
+	Mode      QueryMode         `json:"mode"`
+	Kind      QueryKind         `json:"kind"`
+	Value     string            `json:"value"`
+	RRType    RRType            `json:"rrtype,omitempty"`
+	Bailiwick string            `json:"bailiwick,omitempty"`
+	Exclude   string            `json:"exclude,omitempty"`
+	Summarize bool              `json:"summarize,omitempty"`
+	Options   map[string]string `json:"options,omitempty"`
+}
+
+// MarshalJSON encodes the query as an object, for example in a job queue
+func (q Query) MarshalJSON() ([]byte, error) {
+	values, err := query.Values(q.LookupOptions)
+	if err != nil {
+		return nil, err
+	}
+	qj := queryJSON{
+		Mode: q.Mode, Kind: q.Kind, Value: q.Value, RRType: q.RRType, Bailiwick: q.Bailiwick, Exclude: q.Exclude, Summarize: q.Summarize,
+	}
+	for key := range values {
+		if qj.Options == nil {
+			qj.Options = make(map[string]string)
+		}
+		qj.Options[key] = values.Get(key)
+	}
+	return json.Marshal(qj)
+}
+
+// UnmarshalJSON decodes a query encoded by MarshalJSON
+func (q *Query) UnmarshalJSON(data []byte) error {
+	var qj queryJSON
+	if err := json.Unmarshal(data, &qj); err != nil {
+		return err
+	}
+	values := make(url.Values)
+	for key, v := range qj.Options {
+		values.Set(key, v)
+	}
+	opt, err := parseLookupValues(values)
+	if err != nil {
+		return err
+	}
+	*q = Query{
+		Mode: qj.Mode, Kind: qj.Kind, Value: qj.Value, RRType: qj.RRType, Bailiwick: qj.Bailiwick, Exclude: qj.Exclude, Summarize: qj.Summarize,
+		LookupOptions: opt,
+	}
+	return nil
+}
+
+// Stream runs an rrset or rdata query with the matching RRSetService or RDataService Stream method.
+// Exactly one of the returned streams is non-nil when err is nil, depending on the Mode of the query.
+// Summarize and flex queries are not supported, see Client.SummarizeQuery and FlexService.
+func (q Query) Stream(ctx context.Context, c *Client) (iter.Seq2[RRSet, error], iter.Seq2[RData, error], *Response, error) {
+	if q.Summarize || q.Kind.flex() {
+		return nil, nil, nil, fmt.Errorf("dnsdb: Stream does not support summarize or flex queries")
+	}
+	switch {
+	case q.Mode == QueryRRSet && q.Kind == QueryName:
+		rrsets, resp, err := c.RRSet.StreamName(ctx, q.Value, &RRSetLookupNameOptions{
+			RRType: q.RRType, Bailiwick: q.Bailiwick, LookupOptions: q.LookupOptions,
+		})
+		return rrsets, nil, resp, err
+	case q.Mode != QueryRData:
+	case q.Kind == QueryName:
+		rdata, resp, err := c.RData.StreamName(ctx, q.Value, &RDataLookupNameOptions{RRType: q.RRType, LookupOptions: q.LookupOptions})
+		return nil, rdata, resp, err
+	case q.Kind == QueryIP:
+		ip, ipnet, err := q.ipValue()
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		if ipnet != nil {
+			rdata, resp, err := c.RData.StreamIPNet(ctx, *ipnet, &RDataLookupIPNetOptions{RRType: q.RRType, LookupOptions: q.LookupOptions})
+			return nil, rdata, resp, err
+		}
+		rdata, resp, err := c.RData.StreamIP(ctx, ip, &RDataLookupIPOptions{RRType: q.RRType, LookupOptions: q.LookupOptions})
+		return nil, rdata, resp, err
+	case q.Kind == QueryRaw:
+		raw, err := q.rawValue()
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		rdata, resp, err := c.RData.StreamRaw(ctx, raw, &RDataLookupRawOptions{RRType: q.RRType, LookupOptions: q.LookupOptions})
+		return nil, rdata, resp, err
+	}
+	return nil, nil, nil, fmt.Errorf("dnsdb: unsupported query %s/%s", q.Mode, q.Kind)
+}
+
+// Lookup runs an rrset or rdata query like Stream and collects the results.
+// Exactly one of the returned slices is non-nil when err is nil, depending on the Mode of the query.
+func (q Query) Lookup(ctx context.Context, c *Client) ([]RRSet, []RData, *Response, error) {
+	rrsets, rdata, resp, err := q.Stream(ctx, c)
+	if err != nil {
+		return nil, nil, resp, err
+	}
+	if rrsets != nil {
+		result, resp, err := collectLookup(rrsets, resp, nil)
+		return result, nil, resp, err
+	}
+	result, resp, err := collectLookup(rdata, resp, nil)
+	return nil, result, resp, err
+}
+
+// Paginate runs an rrset or rdata query with the matching RRSetService or RDataService Paginate method.
+// Exactly one of the returned streams is non-nil when err is nil, depending on the Mode of the query.
+func (q Query) Paginate(ctx context.Context, c *Client) (iter.Seq2[RRSet, error], iter.Seq2[RData, error], error) {
+	if q.Summarize || q.Kind.flex() {
+		return nil, nil, fmt.Errorf("dnsdb: Paginate does not support summarize or flex queries")
+	}
+	switch {
+	case q.Mode == QueryRRSet && q.Kind == QueryName:
+		return c.RRSet.PaginateName(ctx, q.Value, &RRSetLookupNameOptions{
+			RRType: q.RRType, Bailiwick: q.Bailiwick, LookupOptions: q.LookupOptions,
+		}), nil, nil
+	case q.Mode != QueryRData:
+	case q.Kind == QueryName:
+		return nil, c.RData.PaginateName(ctx, q.Value, &RDataLookupNameOptions{RRType: q.RRType, LookupOptions: q.LookupOptions}), nil
+	case q.Kind == QueryIP:
+		ip, ipnet, err := q.ipValue()
+		if err != nil {
+			return nil, nil, err
+		}
+		if ipnet != nil {
+			return nil, c.RData.PaginateIPNet(ctx, *ipnet, &RDataLookupIPNetOptions{RRType: q.RRType, LookupOptions: q.LookupOptions}), nil
+		}
+		return nil, c.RData.PaginateIP(ctx, ip, &RDataLookupIPOptions{RRType: q.RRType, LookupOptions: q.LookupOptions}), nil
+	case q.Kind == QueryRaw:
+		raw, err := q.rawValue()
+		if err != nil {
+			return nil, nil, err
+		}
+		return nil, c.RData.PaginateRaw(ctx, raw, &RDataLookupRawOptions{RRType: q.RRType, LookupOptions: q.LookupOptions}), nil
+	}
+	return nil, nil, fmt.Errorf("dnsdb: unsupported query %s/%s", q.Mode, q.Kind)
+}
+
+// SummarizeQuery runs a query with Summarize set
+func (c *Client) SummarizeQuery(ctx context.Context, q Query) (*Summary, *Response, error) {
+	if !q.Summarize {
+		return nil, nil, fmt.Errorf("dnsdb: SummarizeQuery requires a summarize query")
+	}
+	path, _, err := q.path()
+	if err != nil {
+		return nil, nil, err
+	}
+	return summarize(ctx, c, path, q.LookupOptions)
+}
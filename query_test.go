@@ -0,0 +1,158 @@
+package dnsdb
+
+import (
+	"github.com/stretchr/testify/assert"
+
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func Test_Query_String(t *testing.T) {
+	// Verify that every kind of query renders and parses back to itself
+	fenced := Query{Mode: QueryRData, Kind: QueryIP, Value: "192.0.2.0,24"}
+	fenced.Limit, fenced.TimeLastAfter, fenced.Aggr = 10, time.Unix(1000, 0), Bool(false)
+	for expected, q := range map[string]Query{
+		"lookup/rrset/name/example.com/A/com":                                   {Mode: QueryRRSet, Kind: QueryName, Value: "example.com", RRType: RRTypeA, Bailiwick: "com"},
+		"summarize/rdata/name/hq.fsi.io":                                        {Mode: QueryRData, Kind: QueryName, Value: "hq.fsi.io", Summarize: true},
+		"lookup/rdata/ip/192.0.2.1":                                             {Mode: QueryRData, Kind: QueryIP, Value: "192.0.2.1"},
+		"lookup/rdata/raw/0a0b/MX":                                              {Mode: QueryRData, Kind: QueryRaw, Value: "0a0b", RRType: RRTypeMX},
+		"regex/rrnames/%5Ewww%5C..*$/A":                                         {Mode: QueryRRSet, Kind: QueryRegex, Value: `^www\..*$`, RRType: RRTypeA},
+		"glob/rdata/*.example.com":                                              {Mode: QueryRData, Kind: QueryGlob, Value: "*.example.com"},
+		"glob/rrnames/*.example.com?exclude=dev.example.com":                    {Mode: QueryRRSet, Kind: QueryGlob, Value: "*.example.com", Exclude: "dev.example.com"},
+		"lookup/rdata/ip/192.0.2.0,24?aggr=false&limit=10&time_last_after=1000": fenced,
+	} {
+		assert.Equal(t, expected, q.String())
+		actual, err := ParseQueryURL(q.String())
+		assert.Nil(t, err)
+		assert.Equal(t, q, actual)
+	}
+
+	// Verify that an invalid query renders its error
+	assert.Equal(t, "dnsdb: unsupported query dns/name", Query{Mode: "dns", Kind: QueryName}.String())
+	assert.Equal(t, "dnsdb: flex searches cannot be summarized", Query{Mode: QueryRData, Kind: QueryRegex, Summarize: true}.String())
+}
+
+func Test_ParseQueryURL(t *testing.T) {
+	// Verify that full URLs with a version prefix parse
+	q, err := ParseQueryURL("https://api.dnsdb.info/dnsdb/v2/lookup/rrset/name/%2A.example.com/ANY?limit=5&offset=10&swclient=x&version=1")
+	assert.Nil(t, err)
+	expected := Query{Mode: QueryRRSet, Kind: QueryName, Value: "*.example.com", RRType: RRTypeANY}
+	expected.Limit, expected.Offset, expected.SWClient, expected.Version = 5, 10, "x", "1"
+	assert.Equal(t, expected, q)
+
+	// Verify that malformed URLs fail
+	for _, s := range []string{
+		"https://api.dnsdb.info/dnsdb/v2/rate_limit",
+		"lookup/rrset/ip/192.0.2.1",
+		"lookup/rrset/name",
+		"lookup/rdata/name/a/A/b",
+		"regex/names/a",
+		"lookup/rrset/name/a/BOGUS",
+		"lookup/rrset/name/a?limit=ten",
+		"lookup/rrset/name/a?colour=blue",
+		"lookup/rrset/name/a?exclude=b",
+	} {
+		_, err := ParseQueryURL(s)
+		assert.NotNil(t, err, s)
+	}
+}
+
+func Test_Query_JSON(t *testing.T) {
+	// Verify that queries marshal with the API parameter names and round trip
+	q := Query{Mode: QueryRRSet, Kind: QueryName, Value: "example.com", RRType: RRTypeA, Summarize: true}
+	q.Limit, q.TimeFirstBefore, q.HumanTime = 10, time.Unix(2000, 0), true
+	data, err := json.Marshal(q)
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"mode":"rrset","kind":"name","value":"example.com","rrtype":"A","summarize":true,"options":{"limit":"10","time_first_before":"2000","humantime":"true"}}`, string(data))
+	var actual Query
+	assert.Nil(t, json.Unmarshal(data, &actual))
+	assert.Equal(t, q, actual)
+
+	// Verify that invalid options fail
+	assert.NotNil(t, json.Unmarshal([]byte(`{"mode":"rrset","options":{"limit":"x"}}`), &actual))
+}
+
+func Test_Client_NewQueryRequest(t *testing.T) {
+	c := NewClient(nil)
+
+	// Verify that lookups follow the client version and flex always uses APIv2
+	req, err := c.NewQueryRequest(context.Background(), Query{Mode: QueryRRSet, Kind: QueryName, Value: "example.com"})
+	assert.Nil(t, err)
+	assert.Equal(t, "https://api.dnsdb.info/lookup/rrset/name/example.com?", req.URL.String())
+	req, err = c.NewQueryRequest(context.Background(), Query{Mode: QueryRRSet, Kind: QueryGlob, Value: "*.example.com"})
+	assert.Nil(t, err)
+	assert.Equal(t, "/dnsdb/v2/glob/rrnames/*.example.com", req.URL.EscapedPath())
+	assert.Equal(t, "application/x-ndjson", req.Header.Get("Accept"))
+
+	// Verify that invalid queries fail
+	_, err = c.NewQueryRequest(context.Background(), Query{Mode: QueryRData, Kind: QueryIP, Value: "nope"})
+	assert.NotNil(t, err)
+}
+
+func Test_Client_SummarizeQuery(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/summarize/rdata/ip/192.0.2.1", r.URL.Path)
+		io.WriteString(w, `{"count":3,"num_results":2}`)
+	}))
+	defer server.Close()
+	c := NewClient(nil)
+	u, err := url.Parse(server.URL + "/")
+	assert.Nil(t, err)
+	c.BaseURL = u
+
+	// Verify that summarize queries are summarized and lookups are rejected
+	q := Query{Mode: QueryRData, Kind: QueryIP, Value: "192.0.2.1", Summarize: true}
+	summary, _, err := c.SummarizeQuery(context.Background(), q)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(3), *summary.Count)
+	_, _, _, err = q.Lookup(context.Background(), c)
+	assert.NotNil(t, err)
+	q.Summarize = false
+	_, _, err = c.SummarizeQuery(context.Background(), q)
+	assert.NotNil(t, err)
+}
+
+func Test_Query_Paginate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/lookup/rate_limit" {
+			io.WriteString(w, `{"rate":{"reset":"n/a","limit":"unlimited","remaining":"n/a","results_max":1,"offset_max":"n/a"}}`)
+			return
+		}
+		assert.Equal(t, "/lookup/rdata/ip/192.0.2.0,24", r.URL.Path)
+		if r.URL.Query().Get("offset") == "" {
+			io.WriteString(w, `{"rrname":"a.example.","rrtype":"A","rdata":"192.0.2.1"}`)
+		}
+	}))
+	defer server.Close()
+	c := NewClient(nil)
+	u, err := url.Parse(server.URL + "/")
+	assert.Nil(t, err)
+	c.BaseURL = u
+
+	// Verify that rdata queries stream and paginate with the RDataService
+	q := Query{Mode: QueryRData, Kind: QueryIP, Value: "192.0.2.0/24"}
+	rrsets, rdata, _, err := q.Stream(context.Background(), c)
+	assert.Nil(t, err)
+	assert.Nil(t, rrsets)
+	results, err := collect(rdata)
+	assert.Nil(t, err)
+	assert.Len(t, results, 1)
+	rrsets, rdata, err = q.Paginate(context.Background(), c)
+	assert.Nil(t, err)
+	assert.Nil(t, rrsets)
+	results, err = collect(rdata)
+	assert.Nil(t, err)
+	assert.Len(t, results, 1)
+
+	// Verify that invalid and summarize queries fail before any request
+	_, _, err = Query{Mode: QueryRData, Kind: QueryRaw, Value: "zz"}.Paginate(context.Background(), c)
+	assert.NotNil(t, err)
+	_, _, _, err = Query{Mode: QueryRRSet, Kind: QueryName, Value: "a.example", Summarize: true}.Stream(context.Background(), c)
+	assert.NotNil(t, err)
+}
@@ -2,14 +2,15 @@ package dnsdb
 
 // Imports
 import (
+	"context"
 	"encoding/hex"
-	"encoding/json"
-	"io"
+	"iter"
 	"net"
 	"strings"
 )
 
 // RData as described at https://api.dnsdb.info/#rdata-lookups
+// The RRType field stays a *string so existing code keeps compiling, Type is the supported way to get it as an RRType.
 type RData struct {
 	Count         *uint64    `json:"count"`
 	TimeFirst     *Timestamp `json:"time_first"`
@@ -21,160 +22,254 @@ type RData struct {
 	RData         *string    `json:"rdata"`
 }
 
-// decodeRData is a helper function for json streams
-func decodeRData(reader io.Reader) ([]RData, error) {
-	var result []RData
-	dec := json.NewDecoder(reader)
-	for {
-		var r RData
-		if err := dec.Decode(&r); err == io.EOF {
-			break
-		} else if err != nil {
-			return nil, err
-		}
-		result = append(result, r)
-	}
-	return result, nil
-}
-
 // RDataService communicates with the rdata related methods of the DNSDB API.
+// Every lookup takes a context which can be used to cancel the request or set a deadline.
+// The Stream methods yield records one at a time as they are read and must be ranged over exactly once,
+// the response body is closed when iteration ends (including when the caller stops early).
+// The Paginate methods walk the offset of a query, issuing as many requests as needed to fetch every result.
 type RDataService service
 
-// RDataLookupNameOptions specifies the optional parameters to the RDataService.LookupName method.
+// RDataLookupNameOptions specifies the optional parameters to the RDataService.LookupName, StreamName and SummarizeName methods.
 type RDataLookupNameOptions struct {
-	RRType string
+	RRType RRType
 
 	LookupOptions
 }
 
-// LookupName fetches all matching records for the provided name
-func (s *RDataService) LookupName(name string, opt *RDataLookupNameOptions) ([]RData, *Response, error) {
-	path := "lookup/rdata/name/" + name
+// path returns the path below the lookup or summarize prefix and the LookupOptions, or an error if they are invalid
+func (opt *RDataLookupNameOptions) path(name string) (string, LookupOptions, error) {
+	name, err := normalizeName("name", name, true)
+	if err != nil {
+		return "", LookupOptions{}, err
+	}
+	segments := []string{"rdata", "name", name}
 	var lookupOpt LookupOptions
 	if opt != nil {
 		lookupOpt = opt.LookupOptions
 		if opt.RRType != "" {
-			path = path + "/" + opt.RRType
+			if err := opt.RRType.Validate(); err != nil {
+				return "", lookupOpt, err
+			}
+			segments = append(segments, string(opt.RRType))
 		}
 	}
-	req, err := s.client.NewLookupRequest("GET", path, lookupOpt)
+	return joinPath(segments...), lookupOpt, nil
+}
+
+// StreamName fetches all matching records for the provided name
+func (s *RDataService) StreamName(ctx context.Context, name string, opt *RDataLookupNameOptions) (iter.Seq2[RData, error], *Response, error) {
+	path, lookupOpt, err := opt.path(name)
 	if err != nil {
 		return nil, nil, err
 	}
+	return streamLookup[RData](ctx, s.client, "lookup/"+path, lookupOpt)
+}
 
-	resp, err := s.client.Do(req)
+// LookupName fetches all matching records for the provided name
+func (s *RDataService) LookupName(ctx context.Context, name string, opt *RDataLookupNameOptions) ([]RData, *Response, error) {
+	return collectLookup(s.StreamName(ctx, name, opt))
+}
+
+// SummarizeName summarizes all matching records for the provided name without returning them
+func (s *RDataService) SummarizeName(ctx context.Context, name string, opt *RDataLookupNameOptions) (*Summary, *Response, error) {
+	path, lookupOpt, err := opt.path(name)
 	if err != nil {
-		return nil, resp, err
+		return nil, nil, err
 	}
+	return summarize(ctx, s.client, "summarize/"+path, lookupOpt)
+}
 
-	result, err := decodeRData(resp.Body)
-	if err != nil {
-		return nil, resp, err
+// PaginateName fetches all matching records for the provided name one page at a time
+func (s *RDataService) PaginateName(ctx context.Context, name string, opt *RDataLookupNameOptions) iter.Seq2[RData, error] {
+	var pageOpt RDataLookupNameOptions
+	if opt != nil {
+		pageOpt = *opt
 	}
-	return result, resp, err
+	return paginate(ctx, s.client, pageOpt.LookupOptions, func(lookupOpt LookupOptions) (iter.Seq2[RData, error], *Response, error) {
+		pageOpt.LookupOptions = lookupOpt
+		return s.StreamName(ctx, name, &pageOpt)
+	})
 }
 
-// RDataLookupIPOptions specifies the optional parameters to the RDataService.LookupIP method.
+// RDataLookupIPOptions specifies the optional parameters to the RDataService.LookupIP, StreamIP and SummarizeIP methods.
 type RDataLookupIPOptions struct {
-	RRType string
+	RRType RRType
 
 	LookupOptions
 }
 
-// LookupIP fetches all matching records for the provided IP
-func (s *RDataService) LookupIP(ip net.IP, opt *RDataLookupIPOptions) ([]RData, *Response, error) {
-	if opt == nil {
-
+// path returns the path below the lookup or summarize prefix and the LookupOptions, or an error if they are invalid
+func (opt *RDataLookupIPOptions) path(ip net.IP) (string, LookupOptions, error) {
+	if ip == nil {
+		return "", LookupOptions{}, &ValidationError{Field: "ip", Value: "", Reason: "empty"}
 	}
-	path := "lookup/rdata/ip/" + ip.String()
+	segments := []string{"rdata", "ip", ip.String()}
 	var lookupOpt LookupOptions
 	if opt != nil {
 		lookupOpt = opt.LookupOptions
 		if opt.RRType != "" {
-			path = path + "/" + opt.RRType
+			if err := opt.RRType.Validate(); err != nil {
+				return "", lookupOpt, err
+			}
+			segments = append(segments, string(opt.RRType))
 		}
 	}
-	req, err := s.client.NewLookupRequest("GET", path, lookupOpt)
+	return joinPath(segments...), lookupOpt, nil
+}
+
+// StreamIP fetches all matching records for the provided IP
+func (s *RDataService) StreamIP(ctx context.Context, ip net.IP, opt *RDataLookupIPOptions) (iter.Seq2[RData, error], *Response, error) {
+	path, lookupOpt, err := opt.path(ip)
 	if err != nil {
 		return nil, nil, err
 	}
+	return streamLookup[RData](ctx, s.client, "lookup/"+path, lookupOpt)
+}
+
+// LookupIP fetches all matching records for the provided IP
+func (s *RDataService) LookupIP(ctx context.Context, ip net.IP, opt *RDataLookupIPOptions) ([]RData, *Response, error) {
+	return collectLookup(s.StreamIP(ctx, ip, opt))
+}
 
-	resp, err := s.client.Do(req)
+// SummarizeIP summarizes all matching records for the provided IP without returning them
+func (s *RDataService) SummarizeIP(ctx context.Context, ip net.IP, opt *RDataLookupIPOptions) (*Summary, *Response, error) {
+	path, lookupOpt, err := opt.path(ip)
 	if err != nil {
-		return nil, resp, err
+		return nil, nil, err
 	}
+	return summarize(ctx, s.client, "summarize/"+path, lookupOpt)
+}
 
-	result, err := decodeRData(resp.Body)
-	if err != nil {
-		return nil, resp, err
+// PaginateIP fetches all matching records for the provided ip one page at a time
+func (s *RDataService) PaginateIP(ctx context.Context, ip net.IP, opt *RDataLookupIPOptions) iter.Seq2[RData, error] {
+	var pageOpt RDataLookupIPOptions
+	if opt != nil {
+		pageOpt = *opt
 	}
-	return result, resp, err
+	return paginate(ctx, s.client, pageOpt.LookupOptions, func(lookupOpt LookupOptions) (iter.Seq2[RData, error], *Response, error) {
+		pageOpt.LookupOptions = lookupOpt
+		return s.StreamIP(ctx, ip, &pageOpt)
+	})
 }
 
-// RDataLookupIPNetOptions specifies the optional parameters to the RDataService.LookupIPNet method.
+// RDataLookupIPNetOptions specifies the optional parameters to the RDataService.LookupIPNet, StreamIPNet and SummarizeIPNet methods.
 type RDataLookupIPNetOptions struct {
-	RRType string
+	RRType RRType
 
 	LookupOptions
 }
 
-// LookupIPNet fetches all matching records for the provided IPNet
-func (s *RDataService) LookupIPNet(ipnet net.IPNet, opt *RDataLookupIPNetOptions) ([]RData, *Response, error) {
-	path := "lookup/rdata/ip/" + strings.Replace(ipnet.String(), "/", ",", 1)
+// path returns the path below the lookup or summarize prefix and the LookupOptions, or an error if they are invalid
+func (opt *RDataLookupIPNetOptions) path(ipnet net.IPNet) (string, LookupOptions, error) {
+	if ipnet.IP == nil || ipnet.Mask == nil {
+		return "", LookupOptions{}, &ValidationError{Field: "network", Value: ipnet.String(), Reason: "empty"}
+	}
+	segments := []string{"rdata", "ip", strings.Replace(ipnet.String(), "/", ",", 1)}
 	var lookupOpt LookupOptions
 	if opt != nil {
 		lookupOpt = opt.LookupOptions
 		if opt.RRType != "" {
-			path = path + "/" + opt.RRType
+			if err := opt.RRType.Validate(); err != nil {
+				return "", lookupOpt, err
+			}
+			segments = append(segments, string(opt.RRType))
 		}
 	}
-	req, err := s.client.NewLookupRequest("GET", path, lookupOpt)
+	return joinPath(segments...), lookupOpt, nil
+}
+
+// StreamIPNet fetches all matching records for the provided IPNet
+func (s *RDataService) StreamIPNet(ctx context.Context, ipnet net.IPNet, opt *RDataLookupIPNetOptions) (iter.Seq2[RData, error], *Response, error) {
+	path, lookupOpt, err := opt.path(ipnet)
 	if err != nil {
 		return nil, nil, err
 	}
+	return streamLookup[RData](ctx, s.client, "lookup/"+path, lookupOpt)
+}
 
-	resp, err := s.client.Do(req)
+// LookupIPNet fetches all matching records for the provided IPNet
+func (s *RDataService) LookupIPNet(ctx context.Context, ipnet net.IPNet, opt *RDataLookupIPNetOptions) ([]RData, *Response, error) {
+	return collectLookup(s.StreamIPNet(ctx, ipnet, opt))
+}
+
+// SummarizeIPNet summarizes all matching records for the provided IPNet without returning them
+func (s *RDataService) SummarizeIPNet(ctx context.Context, ipnet net.IPNet, opt *RDataLookupIPNetOptions) (*Summary, *Response, error) {
+	path, lookupOpt, err := opt.path(ipnet)
 	if err != nil {
-		return nil, resp, err
+		return nil, nil, err
 	}
+	return summarize(ctx, s.client, "summarize/"+path, lookupOpt)
+}
 
-	result, err := decodeRData(resp.Body)
-	if err != nil {
-		return nil, resp, err
+// PaginateIPNet fetches all matching records for the provided ipnet one page at a time
+func (s *RDataService) PaginateIPNet(ctx context.Context, ipnet net.IPNet, opt *RDataLookupIPNetOptions) iter.Seq2[RData, error] {
+	var pageOpt RDataLookupIPNetOptions
+	if opt != nil {
+		pageOpt = *opt
 	}
-	return result, resp, err
+	return paginate(ctx, s.client, pageOpt.LookupOptions, func(lookupOpt LookupOptions) (iter.Seq2[RData, error], *Response, error) {
+		pageOpt.LookupOptions = lookupOpt
+		return s.StreamIPNet(ctx, ipnet, &pageOpt)
+	})
 }
 
-// RDataLookupRawOptions specifies the optional parameters to the RDataService.LookupRaw method.
+// RDataLookupRawOptions specifies the optional parameters to the RDataService.LookupRaw, StreamRaw and SummarizeRaw methods.
 type RDataLookupRawOptions struct {
-	RRType string
+	RRType RRType
 
 	LookupOptions
 }
 
-// LookupRaw fetches all matching records for the provided raw bytes and optional RRType (set to "")
-func (s *RDataService) LookupRaw(raw []byte, opt *RDataLookupRawOptions) ([]RData, *Response, error) {
-	path := "lookup/rdata/raw/" + hex.EncodeToString(raw)
+// path returns the path below the lookup or summarize prefix and the LookupOptions, or an error if they are invalid
+func (opt *RDataLookupRawOptions) path(raw []byte) (string, LookupOptions, error) {
+	if len(raw) == 0 {
+		return "", LookupOptions{}, &ValidationError{Field: "raw rdata", Value: "", Reason: "empty"}
+	}
+	segments := []string{"rdata", "raw", hex.EncodeToString(raw)}
 	var lookupOpt LookupOptions
 	if opt != nil {
 		lookupOpt = opt.LookupOptions
 		if opt.RRType != "" {
-			path = path + "/" + opt.RRType
+			if err := opt.RRType.Validate(); err != nil {
+				return "", lookupOpt, err
+			}
+			segments = append(segments, string(opt.RRType))
 		}
 	}
-	req, err := s.client.NewLookupRequest("GET", path, lookupOpt)
+	return joinPath(segments...), lookupOpt, nil
+}
+
+// StreamRaw fetches all matching records for the provided raw bytes and optional RRType (set to "")
+func (s *RDataService) StreamRaw(ctx context.Context, raw []byte, opt *RDataLookupRawOptions) (iter.Seq2[RData, error], *Response, error) {
+	path, lookupOpt, err := opt.path(raw)
 	if err != nil {
 		return nil, nil, err
 	}
+	return streamLookup[RData](ctx, s.client, "lookup/"+path, lookupOpt)
+}
 
-	resp, err := s.client.Do(req)
+// LookupRaw fetches all matching records for the provided raw bytes and optional RRType (set to "")
+func (s *RDataService) LookupRaw(ctx context.Context, raw []byte, opt *RDataLookupRawOptions) ([]RData, *Response, error) {
+	return collectLookup(s.StreamRaw(ctx, raw, opt))
+}
+
+// SummarizeRaw summarizes all matching records for the provided raw bytes and optional RRType (set to "") without returning them
+func (s *RDataService) SummarizeRaw(ctx context.Context, raw []byte, opt *RDataLookupRawOptions) (*Summary, *Response, error) {
+	path, lookupOpt, err := opt.path(raw)
 	if err != nil {
-		return nil, resp, err
+		return nil, nil, err
 	}
+	return summarize(ctx, s.client, "summarize/"+path, lookupOpt)
+}
 
-	result, err := decodeRData(resp.Body)
-	if err != nil {
-		return nil, resp, err
+// PaginateRaw fetches all matching records for the provided raw one page at a time
+func (s *RDataService) PaginateRaw(ctx context.Context, raw []byte, opt *RDataLookupRawOptions) iter.Seq2[RData, error] {
+	var pageOpt RDataLookupRawOptions
+	if opt != nil {
+		pageOpt = *opt
 	}
-	return result, resp, err
+	return paginate(ctx, s.client, pageOpt.LookupOptions, func(lookupOpt LookupOptions) (iter.Seq2[RData, error], *Response, error) {
+		pageOpt.LookupOptions = lookupOpt
+		return s.StreamRaw(ctx, raw, &pageOpt)
+	})
 }
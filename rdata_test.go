@@ -3,6 +3,8 @@ package dnsdb
 import (
 	"github.com/stretchr/testify/assert"
 
+	"context"
+	"errors"
 	"io"
 	"net"
 	"net/http"
@@ -23,7 +25,7 @@ func Test_RDataService_LookupName(t *testing.T) {
 	u, err := url.Parse(errorServer.URL)
 	assert.Nil(t, err)
 	c.BaseURL = u
-	_, _, err = c.RData.LookupName("hq.fsi.io", nil)
+	_, _, err = c.RData.LookupName(context.Background(), "hq.fsi.io", nil)
 	assert.NotNil(t, err)
 
 	// Verify that an invalid response fails
@@ -34,7 +36,7 @@ func Test_RDataService_LookupName(t *testing.T) {
 	u, err = url.Parse(invalidServer.URL)
 	assert.Nil(t, err)
 	c.BaseURL = u
-	_, _, err = c.RData.LookupName("hq.fsi.io", nil)
+	_, _, err = c.RData.LookupName(context.Background(), "hq.fsi.io", nil)
 	assert.NotNil(t, err)
 
 	// Verify that it gets and parses a response correctly
@@ -46,7 +48,7 @@ func Test_RDataService_LookupName(t *testing.T) {
 	u, err = url.Parse(reportServer.URL)
 	assert.Nil(t, err)
 	c.BaseURL = u
-	actual, _, err := c.RData.LookupName("hq.fsi.io", &RDataLookupNameOptions{
+	actual, _, err := c.RData.LookupName(context.Background(), "hq.fsi.io", &RDataLookupNameOptions{
 		RRType: "MX",
 	})
 	assert.Nil(t, err)
@@ -82,7 +84,7 @@ func Test_RDataService_LookupIP(t *testing.T) {
 	u, err := url.Parse(errorServer.URL)
 	assert.Nil(t, err)
 	c.BaseURL = u
-	_, _, err = c.RData.LookupIP(net.ParseIP("104.244.13.104"), nil)
+	_, _, err = c.RData.LookupIP(context.Background(), net.ParseIP("104.244.13.104"), nil)
 	assert.NotNil(t, err)
 
 	// Verify that an invalid response fails
@@ -93,7 +95,7 @@ func Test_RDataService_LookupIP(t *testing.T) {
 	u, err = url.Parse(invalidServer.URL)
 	assert.Nil(t, err)
 	c.BaseURL = u
-	_, _, err = c.RData.LookupIP(net.ParseIP("104.244.13.104"), nil)
+	_, _, err = c.RData.LookupIP(context.Background(), net.ParseIP("104.244.13.104"), nil)
 	assert.NotNil(t, err)
 
 	// Verify that it gets and parses a response correctly
@@ -105,7 +107,7 @@ func Test_RDataService_LookupIP(t *testing.T) {
 	u, err = url.Parse(reportServer.URL)
 	assert.Nil(t, err)
 	c.BaseURL = u
-	actual, _, err := c.RData.LookupIP(net.ParseIP("104.244.13.104"), &RDataLookupIPOptions{
+	actual, _, err := c.RData.LookupIP(context.Background(), net.ParseIP("104.244.13.104"), &RDataLookupIPOptions{
 		RRType: "A",
 	})
 	assert.Nil(t, err)
@@ -142,7 +144,7 @@ func Test_RDataService_LookupIPNet(t *testing.T) {
 	u, err := url.Parse(errorServer.URL)
 	assert.Nil(t, err)
 	c.BaseURL = u
-	_, _, err = c.RData.LookupIPNet(*ipnet, nil)
+	_, _, err = c.RData.LookupIPNet(context.Background(), *ipnet, nil)
 	assert.NotNil(t, err)
 
 	// Verify that an invalid response fails
@@ -153,7 +155,7 @@ func Test_RDataService_LookupIPNet(t *testing.T) {
 	u, err = url.Parse(invalidServer.URL)
 	assert.Nil(t, err)
 	c.BaseURL = u
-	_, _, err = c.RData.LookupIPNet(*ipnet, nil)
+	_, _, err = c.RData.LookupIPNet(context.Background(), *ipnet, nil)
 	assert.NotNil(t, err)
 
 	// Verify that it gets and parses a response correctly
@@ -166,7 +168,7 @@ func Test_RDataService_LookupIPNet(t *testing.T) {
 	u, err = url.Parse(reportServer.URL)
 	assert.Nil(t, err)
 	c.BaseURL = u
-	actual, _, err := c.RData.LookupIPNet(*ipnet, &RDataLookupIPNetOptions{
+	actual, _, err := c.RData.LookupIPNet(context.Background(), *ipnet, &RDataLookupIPNetOptions{
 		RRType: "A",
 	})
 	assert.Nil(t, err)
@@ -195,15 +197,27 @@ func Test_RDataService_LookupRaw(t *testing.T) {
 	c := NewClient(nil)
 
 	// Verify that an error response fails
+	requests := 0
 	errorServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
 		http.Error(w, "Oh No", 500)
 	}))
 	defer errorServer.Close()
 	u, err := url.Parse(errorServer.URL)
 	assert.Nil(t, err)
 	c.BaseURL = u
-	_, _, err = c.RData.LookupRaw([]byte{}, nil)
-	assert.NotNil(t, err)
+	_, _, err = c.RData.LookupRaw(context.Background(), []byte("\x68\xF4\x0D\x68"), nil)
+	var errResp *ErrorResponse
+	assert.True(t, errors.As(err, &errResp))
+	assert.Equal(t, 500, errResp.StatusCode)
+
+	// Verify that empty raw bytes fail validation without a request
+	requests = 0
+	_, _, err = c.RData.LookupRaw(context.Background(), []byte{}, nil)
+	var verr *ValidationError
+	assert.True(t, errors.As(err, &verr))
+	assert.Equal(t, "raw rdata", verr.Field)
+	assert.Equal(t, 0, requests)
 
 	// Verify that an invalid response fails
 	invalidServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -213,8 +227,9 @@ func Test_RDataService_LookupRaw(t *testing.T) {
 	u, err = url.Parse(invalidServer.URL)
 	assert.Nil(t, err)
 	c.BaseURL = u
-	_, _, err = c.RData.LookupRaw([]byte{}, nil)
+	_, _, err = c.RData.LookupRaw(context.Background(), []byte("\x68\xF4\x0D\x68"), nil)
 	assert.NotNil(t, err)
+	assert.False(t, errors.As(err, &errResp))
 
 	// Verify that it gets and parses a response correctly
 	reportServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -226,7 +241,7 @@ func Test_RDataService_LookupRaw(t *testing.T) {
 	u, err = url.Parse(reportServer.URL)
 	assert.Nil(t, err)
 	c.BaseURL = u
-	actual, _, err := c.RData.LookupRaw([]byte("\x68\xF4\x0D\x68"), &RDataLookupRawOptions{
+	actual, _, err := c.RData.LookupRaw(context.Background(), []byte("\x68\xF4\x0D\x68"), &RDataLookupRawOptions{
 		RRType: "A",
 	})
 	assert.Nil(t, err)
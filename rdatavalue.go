@@ -0,0 +1,342 @@
+package dnsdb
+
+// Imports
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/netip"
+	"strconv"
+	"strings"
+)
+
+// RDataValue is a typed rdata value parsed from the presentation format returned by the API.
+// It is one of RDataA, RDataAAAA, RDataTarget, RDataMX, RDataSOA, RDataSRV, RDataCAA, RDataNAPTR, RDataTXT,
+// the DNSSEC types RDataDS, RDataDNSKEY, RDataRRSIG, RDataNSEC, RDataNSEC3, RDataNSEC3PARAM or RDataGeneric.
+type RDataValue interface {
+	Type() RRType
+}
+
+// RDataA is the rdata of an A record
+type RDataA struct {
+	Addr netip.Addr
+}
+
+// RDataAAAA is the rdata of an AAAA record
+type RDataAAAA struct {
+	Addr netip.Addr
+}
+
+// RDataTarget is the rdata of the record types which hold a single domain name: NS, CNAME, PTR and DNAME
+type RDataTarget struct {
+	RRType RRType
+	Target string
+}
+
+// RDataMX is the rdata of an MX record
+type RDataMX struct {
+	Preference uint16
+	Exchange   string
+}
+
+// RDataSOA is the rdata of an SOA record
+type RDataSOA struct {
+	MName   string
+	RName   string
+	Serial  uint32
+	Refresh uint32
+	Retry   uint32
+	Expire  uint32
+	Minimum uint32
+}
+
+// RDataSRV is the rdata of an SRV record
+type RDataSRV struct {
+	Priority uint16
+	Weight   uint16
+	Port     uint16
+	Target   string
+}
+
+// RDataCAA is the rdata of a CAA record
+type RDataCAA struct {
+	Flags uint8
+	Tag   string
+	Value string
+}
+
+// RDataNAPTR is the rdata of a NAPTR record
+type RDataNAPTR struct {
+	Order       uint16
+	Preference  uint16
+	Flags       string
+	Services    string
+	Regexp      string
+	Replacement string
+}
+
+// RDataTXT is the rdata of a TXT or SPF record, each character-string is a separate segment
+type RDataTXT struct {
+	RRType   RRType
+	Segments []string
+}
+
+// RDataGeneric is rdata in the RFC 3597 \# form, used by the API for types it cannot present
+type RDataGeneric struct {
+	RRType RRType
+	Data   []byte
+}
+
+func (RDataA) Type() RRType         { return RRTypeA }
+func (RDataAAAA) Type() RRType      { return RRTypeAAAA }
+func (r RDataTarget) Type() RRType  { return r.RRType }
+func (RDataMX) Type() RRType        { return RRTypeMX }
+func (RDataSOA) Type() RRType       { return RRTypeSOA }
+func (RDataSRV) Type() RRType       { return RRTypeSRV }
+func (RDataCAA) Type() RRType       { return RRTypeCAA }
+func (RDataNAPTR) Type() RRType     { return RRTypeNAPTR }
+func (r RDataTXT) Type() RRType     { return r.RRType }
+func (r RDataGeneric) Type() RRType { return r.RRType }
+
+// ErrUnsupportedRRType is returned by ParseRData for types it has no parser for
+var ErrUnsupportedRRType = errors.New("dnsdb: unsupported rrtype")
+
+// rdataFields splits presentation format rdata into fields, removing quotes but keeping escapes intact so that
+// domain names stay in presentation form. Character-strings are decoded with unescapeString.
+func rdataFields(s string) ([]string, error) {
+	var fields []string
+	for i := 0; i < len(s); {
+		if s[i] == ' ' || s[i] == '\t' {
+			i++
+			continue
+		}
+		quoted := s[i] == '"'
+		if quoted {
+			i++
+		}
+		start := i
+		end := -1
+		for ; i < len(s); i++ {
+			c := s[i]
+			if quoted && c == '"' {
+				end = i
+				i++
+				break
+			}
+			if !quoted && (c == ' ' || c == '\t') {
+				break
+			}
+			if c == '\\' {
+				if i+1 == len(s) {
+					return nil, fmt.Errorf("dnsdb: trailing escape in rdata %q", s)
+				}
+				i++ // The escaped character, any further digits of a \DDD escape are ordinary characters here
+			}
+		}
+		if end < 0 {
+			end = i
+		}
+		fields = append(fields, s[start:end])
+	}
+	return fields, nil
+}
+
+// unescapeString decodes the escapes of a character-string, which are either \DDD (decimal) or \X (literal)
+func unescapeString(s string) (string, error) {
+	if !strings.Contains(s, `\`) {
+		return s, nil
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c != '\\' {
+			b.WriteByte(c)
+			continue
+		}
+		if i+3 < len(s) && isDigit(s[i+1]) && isDigit(s[i+2]) && isDigit(s[i+3]) {
+			n, _ := strconv.Atoi(s[i+1 : i+4])
+			if n > 255 {
+				return "", fmt.Errorf("dnsdb: invalid escape in character-string %q", s)
+			}
+			b.WriteByte(byte(n))
+			i += 3
+		} else if i+1 < len(s) {
+			b.WriteByte(s[i+1])
+			i++
+		} else {
+			return "", fmt.Errorf("dnsdb: trailing escape in character-string %q", s)
+		}
+	}
+	return b.String(), nil
+}
+
+// unescapeFields decodes the character-strings at the given indexes of fields in place
+func unescapeFields(fields []string, idxs ...int) error {
+	for _, idx := range idxs {
+		var err error
+		if fields[idx], err = unescapeString(fields[idx]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isDigit reports if c is an ASCII digit
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// parseUint parses the field at idx as an unsigned integer of the given size
+func parseUint(fields []string, idx int, bits int) (uint64, error) {
+	return strconv.ParseUint(fields[idx], 10, bits)
+}
+
+// parseGeneric parses the fields of RFC 3597 \# rdata
+func parseGeneric(t RRType, fields []string) (RDataGeneric, error) {
+	if len(fields) < 2 {
+		return RDataGeneric{}, errors.New("dnsdb: invalid generic rdata")
+	}
+	length, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return RDataGeneric{}, err
+	}
+	data, err := hex.DecodeString(strings.Join(fields[2:], ""))
+	if err != nil {
+		return RDataGeneric{}, err
+	}
+	if len(data) != length {
+		return RDataGeneric{}, errors.New("dnsdb: generic rdata length mismatch")
+	}
+	return RDataGeneric{RRType: t, Data: data}, nil
+}
+
+// ParseRData parses the presentation format rdata of a record of type t
+func ParseRData(t RRType, s string) (RDataValue, error) {
+	t = RRType(strings.ToUpper(string(t)))
+	if strings.HasPrefix(s, `\# `) {
+		return parseGeneric(t, strings.Fields(s))
+	}
+	fields, err := rdataFields(s)
+	if err != nil {
+		return nil, err
+	}
+	wrong := func() (RDataValue, error) {
+		return nil, fmt.Errorf("dnsdb: invalid %s rdata %q", t, s)
+	}
+	switch t {
+	case RRTypeA, RRTypeAAAA:
+		if len(fields) != 1 {
+			return wrong()
+		}
+		addr, err := netip.ParseAddr(fields[0])
+		if err != nil {
+			return nil, err
+		}
+		if t == RRTypeA && addr.Is4() {
+			return RDataA{Addr: addr}, nil
+		} else if t == RRTypeAAAA && addr.Is6() {
+			return RDataAAAA{Addr: addr}, nil
+		}
+		return wrong()
+	case RRTypeNS, RRTypeCNAME, RRTypePTR, RRTypeDNAME:
+		if len(fields) != 1 {
+			return wrong()
+		}
+		return RDataTarget{RRType: t, Target: fields[0]}, nil
+	case RRTypeMX:
+		if len(fields) != 2 {
+			return wrong()
+		}
+		pref, err := parseUint(fields, 0, 16)
+		if err != nil {
+			return nil, err
+		}
+		return RDataMX{Preference: uint16(pref), Exchange: fields[1]}, nil
+	case RRTypeSOA:
+		if len(fields) != 7 {
+			return wrong()
+		}
+		var nums [5]uint32
+		for i := range nums {
+			n, err := parseUint(fields, i+2, 32)
+			if err != nil {
+				return nil, err
+			}
+			nums[i] = uint32(n)
+		}
+		return RDataSOA{MName: fields[0], RName: fields[1], Serial: nums[0], Refresh: nums[1], Retry: nums[2], Expire: nums[3], Minimum: nums[4]}, nil
+	case RRTypeSRV:
+		if len(fields) != 4 {
+			return wrong()
+		}
+		var nums [3]uint16
+		for i := range nums {
+			n, err := parseUint(fields, i, 16)
+			if err != nil {
+				return nil, err
+			}
+			nums[i] = uint16(n)
+		}
+		return RDataSRV{Priority: nums[0], Weight: nums[1], Port: nums[2], Target: fields[3]}, nil
+	case RRTypeCAA:
+		if len(fields) != 3 {
+			return wrong()
+		}
+		flags, err := parseUint(fields, 0, 8)
+		if err != nil {
+			return nil, err
+		}
+		if err := unescapeFields(fields, 2); err != nil {
+			return nil, err
+		}
+		return RDataCAA{Flags: uint8(flags), Tag: fields[1], Value: fields[2]}, nil
+	case RRTypeNAPTR:
+		if len(fields) != 6 {
+			return wrong()
+		}
+		order, err := parseUint(fields, 0, 16)
+		if err != nil {
+			return nil, err
+		}
+		pref, err := parseUint(fields, 1, 16)
+		if err != nil {
+			return nil, err
+		}
+		if err := unescapeFields(fields, 2, 3, 4); err != nil {
+			return nil, err
+		}
+		return RDataNAPTR{Order: uint16(order), Preference: uint16(pref), Flags: fields[2], Services: fields[3], Regexp: fields[4], Replacement: fields[5]}, nil
+	case RRTypeTXT, RRTypeSPF:
+		for i := range fields {
+			if err := unescapeFields(fields, i); err != nil {
+				return nil, err
+			}
+		}
+		return RDataTXT{RRType: t, Segments: fields}, nil
+	}
+	if v, ok, err := parseDNSSEC(t, fields); ok {
+		return v, err
+	}
+	return nil, ErrUnsupportedRRType
+}
+
+// Values parses every rdata of the record, see ParseRData
+func (r RRSet) Values() ([]RDataValue, error) {
+	values := make([]RDataValue, 0, len(r.RData))
+	for _, s := range r.RData {
+		v, err := ParseRData(r.Type(), s)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
+
+// Value parses the rdata of the record, see ParseRData
+func (r RData) Value() (RDataValue, error) {
+	if r.RData == nil {
+		return nil, errors.New("dnsdb: record has no rdata")
+	}
+	return ParseRData(r.Type(), *r.RData)
+}
@@ -0,0 +1,86 @@
+package dnsdb
+
+import (
+	"github.com/stretchr/testify/assert"
+
+	"net/netip"
+	"testing"
+)
+
+func Test_ParseRData(t *testing.T) {
+	for _, test := range []struct {
+		RRType   RRType
+		RData    string
+		Expected RDataValue
+	}{
+		{RRTypeA, "104.244.13.104", RDataA{Addr: netip.MustParseAddr("104.244.13.104")}},
+		{RRTypeAAAA, "2620:11c:f004::104", RDataAAAA{Addr: netip.MustParseAddr("2620:11c:f004::104")}},
+		{RRTypeNS, "ns5.dnsmadeeasy.com.", RDataTarget{RRType: RRTypeNS, Target: "ns5.dnsmadeeasy.com."}},
+		{"cname", "www.fsi.io.", RDataTarget{RRType: RRTypeCNAME, Target: "www.fsi.io."}},
+		{RRTypeMX, "10 hq.fsi.io.", RDataMX{Preference: 10, Exchange: "hq.fsi.io."}},
+		{RRTypeSOA, "ns5.dnsmadeeasy.com. hostmaster.fsi.io. 2016052400 10800 3600 604800 3600", RDataSOA{
+			MName: "ns5.dnsmadeeasy.com.", RName: "hostmaster.fsi.io.", Serial: 2016052400, Refresh: 10800, Retry: 3600, Expire: 604800, Minimum: 3600,
+		}},
+		{RRTypeSRV, "0 5 5060 sip.fsi.io.", RDataSRV{Priority: 0, Weight: 5, Port: 5060, Target: "sip.fsi.io."}},
+		{RRTypeCAA, `0 issue "letsencrypt.org"`, RDataCAA{Flags: 0, Tag: "issue", Value: "letsencrypt.org"}},
+		{RRTypeNAPTR, `100 10 "S" "SIP+D2U" "" _sip._udp.fsi.io.`, RDataNAPTR{
+			Order: 100, Preference: 10, Flags: "S", Services: "SIP+D2U", Regexp: "", Replacement: "_sip._udp.fsi.io.",
+		}},
+		{RRTypeTXT, `"v=spf1 -all" "say \"hi\"\032\\"`, RDataTXT{RRType: RRTypeTXT, Segments: []string{"v=spf1 -all", `say "hi" \`}}},
+		{RRTypeSPF, `"v=spf1 -all"`, RDataTXT{RRType: RRTypeSPF, Segments: []string{"v=spf1 -all"}}},
+		{"TYPE65", `\# 4 0a00 0001`, RDataGeneric{RRType: "TYPE65", Data: []byte{10, 0, 0, 1}}},
+		// Names keep their escapes so they remain valid presentation form, character-strings are decoded
+		{RRTypeMX, `10 a\.b.example.`, RDataMX{Preference: 10, Exchange: `a\.b.example.`}},
+		{RRTypeCNAME, `a\032b.example.`, RDataTarget{RRType: RRTypeCNAME, Target: `a\032b.example.`}},
+		{RRTypeCAA, `0 iodef "mailto:a\"b@example.com"`, RDataCAA{Flags: 0, Tag: "iodef", Value: `mailto:a"b@example.com`}},
+		{RRTypeNAPTR, `100 10 "U" "E2U+sip" "!^.*$!sip:a\\b@example.com!" a\.b.example.`, RDataNAPTR{
+			Order: 100, Preference: 10, Flags: "U", Services: "E2U+sip", Regexp: `!^.*$!sip:a\b@example.com!`, Replacement: `a\.b.example.`,
+		}},
+	} {
+		actual, err := ParseRData(test.RRType, test.RData)
+		assert.Nil(t, err, test.RData)
+		assert.Equal(t, test.Expected, actual, test.RData)
+		assert.Equal(t, test.Expected.Type(), actual.Type())
+	}
+
+	for _, test := range []struct {
+		RRType RRType
+		RData  string
+	}{
+		{RRTypeA, "2620:11c:f004::104"},
+		{RRTypeAAAA, "104.244.13.104"},
+		{RRTypeA, "104.244.13"},
+		{RRTypeMX, "hq.fsi.io."},
+		{RRTypeMX, "65536 hq.fsi.io."},
+		{RRTypeSOA, "ns5.dnsmadeeasy.com. hostmaster.fsi.io. 1 2 3"},
+		{RRTypeTXT, `"bad \999"`},
+		{RRTypeMX, `10 hq.fsi.io\`},
+		{"TYPE65", `\# 5 0a000001`},
+		{"TYPE65", `\# 4 zz`},
+		{RRTypeHINFO, `"PC" "Windows"`},
+	} {
+		_, err := ParseRData(test.RRType, test.RData)
+		assert.NotNil(t, err, test.RData)
+	}
+}
+
+func Test_RRSet_Values(t *testing.T) {
+	values, err := RRSet{RRType: String("MX"), RData: []string{"10 hq.fsi.io.", "20 backup.fsi.io."}}.Values()
+	assert.Nil(t, err)
+	assert.Equal(t, []RDataValue{
+		RDataMX{Preference: 10, Exchange: "hq.fsi.io."},
+		RDataMX{Preference: 20, Exchange: "backup.fsi.io."},
+	}, values)
+
+	_, err = RRSet{RRType: String("MX"), RData: []string{"hq.fsi.io."}}.Values()
+	assert.NotNil(t, err)
+}
+
+func Test_RData_Value(t *testing.T) {
+	value, err := RData{RRType: String("A"), RData: String("104.244.13.104")}.Value()
+	assert.Nil(t, err)
+	assert.Equal(t, RDataA{Addr: netip.MustParseAddr("104.244.13.104")}, value)
+
+	_, err = RData{RRType: String("A")}.Value()
+	assert.NotNil(t, err)
+}
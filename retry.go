@@ -0,0 +1,156 @@
+package dnsdb
+
+// Imports
+import (
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryTransport is a http.RoundTripper that retries transient failures with exponential backoff and jitter.
+// It can wrap or be wrapped by APIKeyTransport.
+//
+// Only idempotent requests are retried, and only after a network error or a 429, 502, 503 or 504 response.
+// When the quota is exhausted it waits until the reset time given by the X-RateLimit-Reset header, if that is within MaxWait.
+// Otherwise a Retry-After header, in seconds or as an HTTP date, is honoured if it is within MaxBackoff or MaxWait.
+// Waits are cut short if the context of the request is done.
+type RetryTransport struct {
+	MaxRetries int           // Most retries of a single request, defaults to 3 if zero.
+	MinBackoff time.Duration // Backoff before the first retry, defaults to 1 second if zero.
+	MaxBackoff time.Duration // Longest backoff between retries, defaults to 30 seconds if zero.
+	MaxWait    time.Duration // Longest wait for the quota to reset, exhausted quotas are not retried if zero.
+
+	// Transport to use when making requests, defaults to http.DefaultTransport if nil.
+	Transport http.RoundTripper
+}
+
+// idempotent reports if the request can safely be sent again
+func idempotent(req *http.Request) bool {
+	switch req.Method {
+	case "GET", "HEAD", "OPTIONS":
+	default:
+		return false
+	}
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// maxBackoff returns MaxBackoff or its default
+func (t *RetryTransport) maxBackoff() time.Duration {
+	if t.MaxBackoff <= 0 {
+		return 30 * time.Second
+	}
+	return t.MaxBackoff
+}
+
+// backoff returns the jittered backoff before the provided retry
+func (t *RetryTransport) backoff(retry int) time.Duration {
+	minBackoff, maxBackoff := t.MinBackoff, t.maxBackoff()
+	if minBackoff <= 0 {
+		minBackoff = time.Second
+	}
+	d := minBackoff << uint(retry)
+	if d > maxBackoff || d <= 0 {
+		d = maxBackoff
+	}
+	// Equal jitter, keeps at least half of the backoff
+	return d/2 + rand.N(d/2+1)
+}
+
+// wait returns how long to wait before retrying after resp, or false if it should not be retried
+func (t *RetryTransport) wait(resp *http.Response, retry int) (time.Duration, bool) {
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests:
+		// An exhausted quota will not succeed until it resets, without the headers it is treated like any other 429
+		if rate := extractRateFromResponse(resp); resp.Header.Get("X-RateLimit-Remaining") == "0" && !rate.Reset.IsZero() {
+			if t.MaxWait <= 0 {
+				return 0, false
+			}
+			d := time.Until(rate.Reset.Time)
+			if d > t.MaxWait {
+				return 0, false
+			}
+			return max(d, 0), true
+		}
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+	default:
+		return 0, false
+	}
+	if d, ok := retryAfter(resp.Header.Get("Retry-After")); ok {
+		if d > t.maxBackoff() && d > t.MaxWait {
+			return 0, false
+		}
+		return d, true
+	}
+	return t.backoff(retry), true
+}
+
+// retryAfter parses a Retry-After header, which is either a number of seconds or an HTTP date
+func retryAfter(s string) (time.Duration, bool) {
+	if s == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(s); err == nil {
+		return time.Duration(max(secs, 0)) * time.Second, true
+	}
+	if t, err := http.ParseTime(s); err == nil {
+		return max(time.Until(t), 0), true
+	}
+	return 0, false
+}
+
+// RoundTrip implements the RoundTripper interface.
+func (t *RetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	transport := t.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	maxRetries := t.MaxRetries
+	if maxRetries <= 0 {
+		maxRetries = 3
+	}
+	if !idempotent(req) {
+		return transport.RoundTrip(req)
+	}
+
+	for retry := 0; ; retry++ {
+		attempt := req
+		if retry > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			attempt = req.Clone(req.Context())
+			attempt.Body = body
+		}
+		resp, err := transport.RoundTrip(attempt)
+		if retry >= maxRetries || req.Context().Err() != nil {
+			return resp, err
+		}
+
+		var d time.Duration
+		if err != nil {
+			d = t.backoff(retry)
+		} else {
+			var ok bool
+			if d, ok = t.wait(resp, retry); !ok {
+				return resp, nil
+			}
+			// Drain the body so the connection can be reused
+			io.Copy(io.Discard, io.LimitReader(resp.Body, maxErrorBody))
+			resp.Body.Close()
+		}
+
+		if err := sleep(req.Context(), d); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// Client returns a *http.Client that retries transient failures
+func (t *RetryTransport) Client() *http.Client {
+	return &http.Client{
+		Transport: t,
+	}
+}
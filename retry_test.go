@@ -0,0 +1,142 @@
+package dnsdb
+
+import (
+	"github.com/stretchr/testify/assert"
+
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Make a test transport which replays canned responses
+type RetryTransportTest struct {
+	Responses []*http.Response
+	Errors    []error
+	Calls     int
+}
+
+func (t *RetryTransportTest) RoundTrip(req *http.Request) (*http.Response, error) {
+	i := t.Calls
+	t.Calls++
+	if i < len(t.Errors) && t.Errors[i] != nil {
+		return nil, t.Errors[i]
+	}
+	return t.Responses[i], nil
+}
+
+// testResponse makes a response with the provided status code and headers
+func testResponse(status int, headers ...string) *http.Response {
+	resp := &http.Response{
+		StatusCode: status,
+		Header:     http.Header{},
+		Body:       io.NopCloser(strings.NewReader("")),
+	}
+	for i := 0; i+1 < len(headers); i += 2 {
+		resp.Header.Set(headers[i], headers[i+1])
+	}
+	return resp
+}
+
+func Test_RetryTransport(t *testing.T) {
+	newRequest := func(method string) *http.Request {
+		req, _ := http.NewRequest(method, "https://api.dnsdb.info/lookup/rrset/name/fsi.io", nil)
+		return req
+	}
+
+	// Verify that transient failures are retried until success
+	test := &RetryTransportTest{
+		Responses: []*http.Response{nil, testResponse(503), testResponse(200)},
+		Errors:    []error{errors.New("connection reset")},
+	}
+	retry := &RetryTransport{MinBackoff: time.Millisecond, MaxBackoff: 2 * time.Millisecond, Transport: test}
+	resp, err := retry.RoundTrip(newRequest("GET"))
+	assert.Nil(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, 3, test.Calls)
+
+	// Verify that retries are bounded
+	test = &RetryTransportTest{Responses: []*http.Response{testResponse(502), testResponse(502), testResponse(502)}}
+	retry = &RetryTransport{MaxRetries: 2, MinBackoff: time.Millisecond, Transport: test}
+	resp, err = retry.RoundTrip(newRequest("GET"))
+	assert.Nil(t, err)
+	assert.Equal(t, 502, resp.StatusCode)
+	assert.Equal(t, 3, test.Calls)
+
+	// Verify that client errors and non-idempotent requests are never retried
+	test = &RetryTransportTest{Responses: []*http.Response{testResponse(400), testResponse(200)}}
+	retry = &RetryTransport{MinBackoff: time.Millisecond, Transport: test}
+	resp, err = retry.RoundTrip(newRequest("GET"))
+	assert.Nil(t, err)
+	assert.Equal(t, 400, resp.StatusCode)
+	assert.Equal(t, 1, test.Calls)
+	test = &RetryTransportTest{Responses: []*http.Response{testResponse(503), testResponse(200)}}
+	retry = &RetryTransport{MinBackoff: time.Millisecond, Transport: test}
+	resp, err = retry.RoundTrip(newRequest("POST"))
+	assert.Nil(t, err)
+	assert.Equal(t, 503, resp.StatusCode)
+	assert.Equal(t, 1, test.Calls)
+
+	// Verify that an exhausted quota is not retried without a MaxWait
+	reset := strconv.FormatInt(time.Now().Add(20*time.Millisecond).Unix(), 10)
+	test = &RetryTransportTest{Responses: []*http.Response{testResponse(429, "X-RateLimit-Remaining", "0", "X-RateLimit-Reset", reset), testResponse(200)}}
+	retry = &RetryTransport{MinBackoff: time.Millisecond, Transport: test}
+	resp, err = retry.RoundTrip(newRequest("GET"))
+	assert.Nil(t, err)
+	assert.Equal(t, 429, resp.StatusCode)
+	assert.Equal(t, 1, test.Calls)
+
+	// Verify that an exhausted quota is waited for within MaxWait
+	test = &RetryTransportTest{Responses: []*http.Response{testResponse(429, "X-RateLimit-Remaining", "0", "X-RateLimit-Reset", reset), testResponse(200)}}
+	retry = &RetryTransport{MinBackoff: time.Millisecond, MaxWait: 2 * time.Second, Transport: test}
+	resp, err = retry.RoundTrip(newRequest("GET"))
+	assert.Nil(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, 2, test.Calls)
+
+	// Verify that a reset beyond MaxWait is not waited for
+	reset = strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10)
+	test = &RetryTransportTest{Responses: []*http.Response{testResponse(429, "X-RateLimit-Remaining", "0", "X-RateLimit-Reset", reset), testResponse(200)}}
+	retry = &RetryTransport{MinBackoff: time.Millisecond, MaxWait: time.Minute, Transport: test}
+	resp, err = retry.RoundTrip(newRequest("GET"))
+	assert.Nil(t, err)
+	assert.Equal(t, 429, resp.StatusCode)
+
+	// Verify that a 429 without rate limit headers is retried
+	test = &RetryTransportTest{Responses: []*http.Response{testResponse(429), testResponse(200)}}
+	retry = &RetryTransport{MinBackoff: time.Millisecond, Transport: test}
+	resp, err = retry.RoundTrip(newRequest("GET"))
+	assert.Nil(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, 2, test.Calls)
+
+	// Verify that Retry-After is honoured as an HTTP date, and given up on beyond MaxBackoff and MaxWait
+	date := time.Now().Add(-time.Second).UTC().Format(http.TimeFormat)
+	test = &RetryTransportTest{Responses: []*http.Response{testResponse(503, "Retry-After", date), testResponse(200)}}
+	retry = &RetryTransport{Transport: test}
+	resp, err = retry.RoundTrip(newRequest("GET"))
+	assert.Nil(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+	test = &RetryTransportTest{Responses: []*http.Response{testResponse(503, "Retry-After", "3600"), testResponse(200)}}
+	retry = &RetryTransport{MaxWait: time.Minute, Transport: test}
+	resp, err = retry.RoundTrip(newRequest("GET"))
+	assert.Nil(t, err)
+	assert.Equal(t, 503, resp.StatusCode)
+	assert.Equal(t, 1, test.Calls)
+
+	// Verify that the wait is cut short by the request context
+	test = &RetryTransportTest{Responses: []*http.Response{testResponse(503, "Retry-After", "20"), testResponse(200)}}
+	retry = &RetryTransport{Transport: test}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = retry.RoundTrip(newRequest("GET").WithContext(ctx))
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.Equal(t, 1, test.Calls)
+
+	// Verify it returns a correct client
+	assert.Equal(t, retry, retry.Client().Transport)
+}
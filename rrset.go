@@ -2,11 +2,12 @@ package dnsdb
 
 // Imports
 import (
-	"encoding/json"
-	"io"
+	"context"
+	"iter"
 )
 
 // RRSet as described at https://api.dnsdb.info/#rrest-results
+// The RRType field stays a *string so existing code keeps compiling, Type is the supported way to get it as an RRType.
 type RRSet struct {
 	Count         *uint64    `json:"count"`
 	Bailiwick     *string    `json:"bailiwick"`
@@ -20,58 +21,84 @@ type RRSet struct {
 }
 
 // RRSetService communicates with the rrset related methods of the DNSDB API.
+// Every lookup takes a context which can be used to cancel the request or set a deadline.
+// The Stream methods yield records one at a time as they are read and must be ranged over exactly once,
+// the response body is closed when iteration ends (including when the caller stops early).
+// The Paginate methods walk the offset of a query, issuing as many requests as needed to fetch every result.
+// Owner names may be a left ("*.example.com") or right ("www.example.*") wildcard, see LeftWildcard and RightWildcard.
 type RRSetService service
 
-// RRSetLookupNameOptions specifies the optional parameters to the RRSetService.LookupName method.
+// RRSetLookupNameOptions specifies the optional parameters to the RRSetService.LookupName, StreamName and SummarizeName methods.
 type RRSetLookupNameOptions struct {
-	RRType    string
+	RRType    RRType
 	Bailiwick string
 
 	LookupOptions
 }
 
-// decodeRRSet is a helper function for json streams
-func decodeRRSet(reader io.Reader) ([]RRSet, error) {
-	var result []RRSet
-	dec := json.NewDecoder(reader)
-	for {
-		var r RRSet
-		if err := dec.Decode(&r); err == io.EOF {
-			break
-		} else if err != nil {
-			return nil, err
-		}
-		result = append(result, r)
+// path returns the path below the lookup or summarize prefix and the LookupOptions, or an error if they are invalid
+func (opt *RRSetLookupNameOptions) path(ownerName string) (string, LookupOptions, error) {
+	ownerName, err := normalizeName("name", ownerName, true)
+	if err != nil {
+		return "", LookupOptions{}, err
 	}
-	return result, nil
-}
-
-// LookupName fetches all matching records for the given owner name
-func (s *RRSetService) LookupName(ownerName string, opt *RRSetLookupNameOptions) ([]RRSet, *Response, error) {
-	path := "lookup/rrset/name/" + ownerName
+	segments := []string{"rrset", "name", ownerName}
 	var lookupOpt LookupOptions
 	if opt != nil {
 		lookupOpt = opt.LookupOptions
-		if opt.RRType != "" {
-			path = path + "/" + opt.RRType
-			if opt.Bailiwick != "" {
-				path = path + "/" + opt.Bailiwick
+		rrtype := opt.RRType
+		// The bailiwick is the last segment so it needs an rrtype before it
+		if rrtype == "" && opt.Bailiwick != "" {
+			rrtype = RRTypeANY
+		}
+		if rrtype != "" {
+			if err := rrtype.Validate(); err != nil {
+				return "", lookupOpt, err
+			}
+			segments = append(segments, string(rrtype))
+		}
+		if opt.Bailiwick != "" {
+			bailiwick, err := normalizeName("bailiwick", opt.Bailiwick, false)
+			if err != nil {
+				return "", lookupOpt, err
 			}
+			segments = append(segments, bailiwick)
 		}
 	}
-	req, err := s.client.NewLookupRequest("GET", path, lookupOpt)
+	return joinPath(segments...), lookupOpt, nil
+}
+
+// StreamName fetches all matching records for the given owner name
+func (s *RRSetService) StreamName(ctx context.Context, ownerName string, opt *RRSetLookupNameOptions) (iter.Seq2[RRSet, error], *Response, error) {
+	path, lookupOpt, err := opt.path(ownerName)
 	if err != nil {
 		return nil, nil, err
 	}
+	return streamLookup[RRSet](ctx, s.client, "lookup/"+path, lookupOpt)
+}
+
+// LookupName fetches all matching records for the given owner name
+func (s *RRSetService) LookupName(ctx context.Context, ownerName string, opt *RRSetLookupNameOptions) ([]RRSet, *Response, error) {
+	return collectLookup(s.StreamName(ctx, ownerName, opt))
+}
 
-	resp, err := s.client.Do(req)
+// SummarizeName summarizes all matching records for the given owner name without returning them
+func (s *RRSetService) SummarizeName(ctx context.Context, ownerName string, opt *RRSetLookupNameOptions) (*Summary, *Response, error) {
+	path, lookupOpt, err := opt.path(ownerName)
 	if err != nil {
-		return nil, resp, err
+		return nil, nil, err
 	}
+	return summarize(ctx, s.client, "summarize/"+path, lookupOpt)
+}
 
-	result, err := decodeRRSet(resp.Body)
-	if err != nil {
-		return nil, resp, err
+// PaginateName fetches all matching records for the given owner name one page at a time
+func (s *RRSetService) PaginateName(ctx context.Context, ownerName string, opt *RRSetLookupNameOptions) iter.Seq2[RRSet, error] {
+	var pageOpt RRSetLookupNameOptions
+	if opt != nil {
+		pageOpt = *opt
 	}
-	return result, resp, err
+	return paginate(ctx, s.client, pageOpt.LookupOptions, func(lookupOpt LookupOptions) (iter.Seq2[RRSet, error], *Response, error) {
+		pageOpt.LookupOptions = lookupOpt
+		return s.StreamName(ctx, ownerName, &pageOpt)
+	})
 }
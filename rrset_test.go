@@ -3,11 +3,13 @@ package dnsdb
 import (
 	"github.com/stretchr/testify/assert"
 
+	"context"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"testing"
+	"time"
 )
 
 func Test_RRSetService_LookupName(t *testing.T) {
@@ -22,7 +24,7 @@ func Test_RRSetService_LookupName(t *testing.T) {
 	u, err := url.Parse(errorServer.URL)
 	assert.Nil(t, err)
 	c.BaseURL = u
-	_, _, err = c.RRSet.LookupName("*.farsightsecurity.com", nil)
+	_, _, err = c.RRSet.LookupName(context.Background(), "*.farsightsecurity.com", nil)
 	assert.NotNil(t, err)
 
 	// Verify that it gets and parses a response correctly
@@ -34,7 +36,7 @@ func Test_RRSetService_LookupName(t *testing.T) {
 	u, err = url.Parse(reportServer.URL)
 	assert.Nil(t, err)
 	c.BaseURL = u
-	actual, _, err := c.RRSet.LookupName("*.farsightsecurity.com", &RRSetLookupNameOptions{
+	actual, _, err := c.RRSet.LookupName(context.Background(), "*.farsightsecurity.com", &RRSetLookupNameOptions{
 		RRType:    "NS",
 		Bailiwick: "farsightsecurity.com",
 	})
@@ -69,3 +71,61 @@ func Test_RRSetService_LookupName(t *testing.T) {
 		},
 	}, actual)
 }
+
+func Test_RRSetService_LookupName_Cancel(t *testing.T) {
+	// Setup a client
+	c := NewClient(nil)
+
+	// A server that never finishes the response
+	unblock := make(chan struct{})
+	slowServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"count":51,"time_first":1372688083,"time_last":1374023864,"rrname":"farsightsecurity.com.","rrtype":"NS","bailiwick":"farsightsecurity.com.","rdata":["ns.lah1.vix.com."]}`+"\n")
+		w.(http.Flusher).Flush()
+		<-unblock
+	}))
+	defer slowServer.Close()
+	defer close(unblock)
+	u, err := url.Parse(slowServer.URL)
+	assert.Nil(t, err)
+	c.BaseURL = u
+
+	// Verify an already cancelled context never sends the request
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, _, err = c.RRSet.LookupName(ctx, "farsightsecurity.com", nil)
+	assert.Equal(t, context.Canceled, err)
+
+	// Verify a deadline aborts reading the body mid-stream
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, _, err = c.RRSet.LookupName(ctx, "farsightsecurity.com", nil)
+	assert.NotNil(t, err)
+}
+
+func Test_RRSetService_StreamName(t *testing.T) {
+	// Setup a client
+	c := NewClient(nil)
+
+	// Verify that records are yielded one at a time
+	reportServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/lookup/rrset/name/farsightsecurity.com/NS", r.URL.Path)
+		io.WriteString(w, `{"count":51,"rrname":"farsightsecurity.com.","rrtype":"NS","rdata":["ns.lah1.vix.com."]}
+{"count":495241,"rrname":"farsightsecurity.com.","rrtype":"NS","rdata":["ns5.dnsmadeeasy.com."]}`)
+	}))
+	defer reportServer.Close()
+	u, err := url.Parse(reportServer.URL)
+	assert.Nil(t, err)
+	c.BaseURL = u
+	seq, resp, err := c.RRSet.StreamName(context.Background(), "farsightsecurity.com", &RRSetLookupNameOptions{
+		RRType: "NS",
+	})
+	assert.Nil(t, err)
+	assert.NotNil(t, resp)
+	var counts []uint64
+	for r, err := range seq {
+		assert.Nil(t, err)
+		counts = append(counts, *r.Count)
+		break
+	}
+	assert.Equal(t, []uint64{51}, counts)
+}
@@ -0,0 +1,113 @@
+package dnsdb
+
+// Imports
+import (
+	"strconv"
+	"strings"
+)
+
+// RRType is a DNS resource record type as used in lookup paths and results
+type RRType string
+
+// Common resource record types
+const (
+	RRTypeA          RRType = "A"
+	RRTypeNS         RRType = "NS"
+	RRTypeCNAME      RRType = "CNAME"
+	RRTypeSOA        RRType = "SOA"
+	RRTypePTR        RRType = "PTR"
+	RRTypeHINFO      RRType = "HINFO"
+	RRTypeMX         RRType = "MX"
+	RRTypeTXT        RRType = "TXT"
+	RRTypeRP         RRType = "RP"
+	RRTypeAFSDB      RRType = "AFSDB"
+	RRTypeAAAA       RRType = "AAAA"
+	RRTypeLOC        RRType = "LOC"
+	RRTypeSRV        RRType = "SRV"
+	RRTypeNAPTR      RRType = "NAPTR"
+	RRTypeKX         RRType = "KX"
+	RRTypeCERT       RRType = "CERT"
+	RRTypeDNAME      RRType = "DNAME"
+	RRTypeSSHFP      RRType = "SSHFP"
+	RRTypeTLSA       RRType = "TLSA"
+	RRTypeSMIMEA     RRType = "SMIMEA"
+	RRTypeHIP        RRType = "HIP"
+	RRTypeOPENPGPKEY RRType = "OPENPGPKEY"
+	RRTypeSVCB       RRType = "SVCB"
+	RRTypeHTTPS      RRType = "HTTPS"
+	RRTypeSPF        RRType = "SPF"
+	RRTypeURI        RRType = "URI"
+	RRTypeCAA        RRType = "CAA"
+)
+
+// DNSSEC resource record types
+const (
+	RRTypeDS         RRType = "DS"
+	RRTypeRRSIG      RRType = "RRSIG"
+	RRTypeNSEC       RRType = "NSEC"
+	RRTypeDNSKEY     RRType = "DNSKEY"
+	RRTypeNSEC3      RRType = "NSEC3"
+	RRTypeNSEC3PARAM RRType = "NSEC3PARAM"
+	RRTypeCDS        RRType = "CDS"
+	RRTypeCDNSKEY    RRType = "CDNSKEY"
+	RRTypeDLV        RRType = "DLV"
+)
+
+// Pseudo-types understood by the DNSDB API
+const (
+	RRTypeANY       RRType = "ANY"        // Any type except the DNSSEC types
+	RRTypeANYDNSSEC RRType = "ANY-DNSSEC" // Any of the DNSSEC types
+)
+
+// knownRRTypes is the set of RRTypes accepted by Validate in addition to the generic TYPE#### form
+var knownRRTypes = map[RRType]bool{}
+
+func init() {
+	for _, t := range []RRType{
+		RRTypeA, RRTypeNS, RRTypeCNAME, RRTypeSOA, RRTypePTR, RRTypeHINFO, RRTypeMX, RRTypeTXT, RRTypeRP,
+		RRTypeAFSDB, RRTypeAAAA, RRTypeLOC, RRTypeSRV, RRTypeNAPTR, RRTypeKX, RRTypeCERT, RRTypeDNAME,
+		RRTypeSSHFP, RRTypeTLSA, RRTypeSMIMEA, RRTypeHIP, RRTypeOPENPGPKEY, RRTypeSVCB, RRTypeHTTPS,
+		RRTypeSPF, RRTypeURI, RRTypeCAA,
+		RRTypeDS, RRTypeRRSIG, RRTypeNSEC, RRTypeDNSKEY, RRTypeNSEC3, RRTypeNSEC3PARAM, RRTypeCDS,
+		RRTypeCDNSKEY, RRTypeDLV,
+		RRTypeANY, RRTypeANYDNSSEC,
+	} {
+		knownRRTypes[t] = true
+	}
+}
+
+// GenericRRType returns the RFC 3597 TYPE#### form of a numeric type
+func GenericRRType(n uint16) RRType {
+	return RRType("TYPE" + strconv.Itoa(int(n)))
+}
+
+// Validate returns an error if the type is neither a known mnemonic, a pseudo-type nor of the form TYPE####.
+// Mnemonics are matched case-insensitively.
+func (t RRType) Validate() error {
+	upper := RRType(strings.ToUpper(string(t)))
+	if knownRRTypes[upper] {
+		return nil
+	}
+	if n, ok := strings.CutPrefix(string(upper), "TYPE"); ok {
+		if _, err := strconv.ParseUint(n, 10, 16); err == nil {
+			return nil
+		}
+	}
+	return &ValidationError{Field: "rrtype", Value: string(t)}
+}
+
+// Type returns the type of the record
+func (r RRSet) Type() RRType {
+	if r.RRType == nil {
+		return ""
+	}
+	return RRType(*r.RRType)
+}
+
+// Type returns the type of the record
+func (r RData) Type() RRType {
+	if r.RRType == nil {
+		return ""
+	}
+	return RRType(*r.RRType)
+}
@@ -0,0 +1,45 @@
+package dnsdb
+
+import (
+	"github.com/stretchr/testify/assert"
+
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func Test_RRType_Validate(t *testing.T) {
+	for _, valid := range []RRType{RRTypeA, RRTypeDNSKEY, RRTypeANY, RRTypeANYDNSSEC, "mx", "TYPE65", "TYPE0", "type65535", GenericRRType(64)} {
+		assert.Nil(t, valid.Validate(), string(valid))
+	}
+	for _, invalid := range []RRType{"", "AA", "A/NS", "TYPE", "TYPE65536", "TYPE-1", "TYPE+1", "ANYDNSSEC"} {
+		assert.NotNil(t, invalid.Validate(), string(invalid))
+	}
+}
+
+func Test_RRSet_Type(t *testing.T) {
+	assert.Equal(t, RRTypeNS, RRSet{RRType: String("NS")}.Type())
+	assert.Equal(t, RRType(""), RRSet{}.Type())
+	assert.Equal(t, RRTypeMX, RData{RRType: String("MX")}.Type())
+}
+
+func Test_RRSetService_LookupName_InvalidRRType(t *testing.T) {
+	// Setup a client
+	c := NewClient(nil)
+
+	// Verify that an invalid rrtype never reaches the server
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request for %s", r.URL.Path)
+	}))
+	defer server.Close()
+	u, err := url.Parse(server.URL)
+	assert.Nil(t, err)
+	c.BaseURL = u
+	_, resp, err := c.RRSet.LookupName(context.Background(), "farsightsecurity.com", &RRSetLookupNameOptions{
+		RRType: "NSS",
+	})
+	assert.NotNil(t, err)
+	assert.Nil(t, resp)
+}
@@ -0,0 +1,85 @@
+package dnsdb
+
+// Imports
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+)
+
+// Condition is a Streaming Application Format (SAF) condition as described at https://docs.dnsdb.info/dnsdb-apiv2/#streaming-application-format
+type Condition string
+
+const (
+	ConditionBegin     Condition = "begin"     // The first line of a stream
+	ConditionOngoing   Condition = "ongoing"   // A keep-alive while more results are pending
+	ConditionSucceeded Condition = "succeeded" // All results were returned
+	ConditionLimited   Condition = "limited"   // The results were truncated by a limit, see the message for which one
+	ConditionFailed    Condition = "failed"    // An error occurred partway through the results
+)
+
+// Terminal reports if the condition ends a stream
+func (c Condition) Terminal() bool {
+	return c == ConditionSucceeded || c == ConditionLimited || c == ConditionFailed
+}
+
+// ErrTruncated is returned when a SAF stream ends without a terminal condition
+var ErrTruncated = errors.New("dnsdb: stream ended without a terminal condition")
+
+// safLine is a single line of a SAF stream
+type safLine[T any] struct {
+	Cond Condition `json:"cond"`
+	Obj  *T        `json:"obj"`
+	Msg  string    `json:"msg"`
+}
+
+// decodeSAF returns an iterator over the objects in a SAF stream, recording each condition on resp as it is read.
+// The body is closed once the stream is exhausted, the caller stops iterating or ctx is done.
+// If noResultsAsError is set a stream which succeeds without any objects yields ErrNoResults.
+func decodeSAF[T any](ctx context.Context, resp *Response, noResultsAsError bool) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		defer resp.Body.Close()
+		dec := json.NewDecoder(resp.Body)
+		var count int
+		for {
+			var zero T
+			if err := ctx.Err(); err != nil {
+				yield(zero, err)
+				return
+			}
+			var line safLine[T]
+			if err := dec.Decode(&line); err == io.EOF {
+				yield(zero, ErrTruncated)
+				return
+			} else if err != nil {
+				yield(zero, err)
+				return
+			}
+			if line.Cond != "" {
+				resp.Condition = line.Cond
+				resp.Message = line.Msg
+			}
+			if line.Obj != nil {
+				count++
+				if !yield(*line.Obj, nil) {
+					return
+				}
+			}
+			switch line.Cond {
+			case ConditionSucceeded:
+				if count == 0 && noResultsAsError {
+					yield(zero, ErrNoResults)
+				}
+				return
+			case ConditionLimited:
+				return
+			case ConditionFailed:
+				yield(zero, fmt.Errorf("dnsdb: query failed: %s", line.Msg))
+				return
+			}
+		}
+	}
+}
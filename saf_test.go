@@ -0,0 +1,101 @@
+package dnsdb
+
+import (
+	"github.com/stretchr/testify/assert"
+
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func Test_decodeSAF(t *testing.T) {
+	// Setup a client speaking APIv2
+	c := NewClient(nil)
+	c.APIVersion = APIv2
+
+	var body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/dnsdb/v2/lookup/rdata/name/hq.fsi.io/MX", r.URL.Path)
+		assert.Equal(t, "application/x-ndjson", r.Header.Get("Accept"))
+		io.WriteString(w, body)
+	}))
+	defer server.Close()
+	u, err := url.Parse(server.URL)
+	assert.Nil(t, err)
+	c.BaseURL = u
+	opt := &RDataLookupNameOptions{RRType: "MX"}
+
+	// Verify that a complete stream is decoded and the condition is surfaced
+	body = `{"cond":"begin"}
+{"obj":{"count":45644,"time_first":1372706073,"time_last":1468330740,"rrname":"fsi.io.","rrtype":"MX","rdata":"10 hq.fsi.io."}}
+{"cond":"ongoing"}
+{"obj":{"count":19304,"time_first":1374098929,"time_last":1468333042,"rrname":"farsightsecurity.com.","rrtype":"MX","rdata":"10 hq.fsi.io."}}
+{"cond":"succeeded"}
+`
+	actual, resp, err := c.RData.LookupName(context.Background(), "hq.fsi.io", opt)
+	assert.Nil(t, err)
+	assert.Equal(t, ConditionSucceeded, resp.Condition)
+	assert.Equal(t, []RData{
+		RData{
+			Count:     Uint64(45644),
+			TimeFirst: NewTimestamp(1372706073),
+			TimeLast:  NewTimestamp(1468330740),
+			RRName:    String("fsi.io."),
+			RRType:    String("MX"),
+			RData:     String("10 hq.fsi.io."),
+		},
+		RData{
+			Count:     Uint64(19304),
+			TimeFirst: NewTimestamp(1374098929),
+			TimeLast:  NewTimestamp(1468333042),
+			RRName:    String("farsightsecurity.com."),
+			RRType:    String("MX"),
+			RData:     String("10 hq.fsi.io."),
+		},
+	}, actual)
+
+	// Verify that a limited stream is not an error but is reported
+	body = `{"cond":"begin"}
+{"obj":{"count":45644,"rrname":"fsi.io.","rrtype":"MX","rdata":"10 hq.fsi.io."}}
+{"cond":"limited","msg":"Result limit reached"}
+`
+	actual, resp, err = c.RData.LookupName(context.Background(), "hq.fsi.io", opt)
+	assert.Nil(t, err)
+	assert.Len(t, actual, 1)
+	assert.Equal(t, ConditionLimited, resp.Condition)
+	assert.Equal(t, "Result limit reached", resp.Message)
+
+	// Verify that a failed stream is an error
+	body = `{"cond":"begin"}
+{"cond":"failed","msg":"Query timed out"}
+`
+	_, resp, err = c.RData.LookupName(context.Background(), "hq.fsi.io", opt)
+	assert.NotNil(t, err)
+	assert.Equal(t, ConditionFailed, resp.Condition)
+	assert.Equal(t, "Query timed out", resp.Message)
+
+	// Verify that a stream without a terminal condition is an error
+	body = `{"cond":"begin"}
+{"obj":{"count":45644,"rrname":"fsi.io.","rrtype":"MX","rdata":"10 hq.fsi.io."}}
+`
+	_, resp, err = c.RData.LookupName(context.Background(), "hq.fsi.io", opt)
+	assert.Equal(t, ErrTruncated, err)
+	assert.Equal(t, ConditionBegin, resp.Condition)
+	assert.False(t, resp.Condition.Terminal())
+
+	// Verify that a stream which succeeds without objects is an empty result
+	body = `{"cond":"begin"}
+{"cond":"succeeded"}
+`
+	actual, _, err = c.RData.LookupName(context.Background(), "hq.fsi.io", opt)
+	assert.Nil(t, err)
+	assert.Equal(t, []RData{}, actual)
+
+	// Verify that it is ErrNoResults when opted in
+	c.NoResultsAsError = true
+	_, _, err = c.RData.LookupName(context.Background(), "hq.fsi.io", opt)
+	assert.True(t, IsNoResults(err))
+}
@@ -0,0 +1,104 @@
+package dnsdb
+
+// Imports
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"iter"
+	"net/http"
+)
+
+// decodeStream returns an iterator over the newline-delimited JSON values in body.
+// The body is closed once the stream is exhausted, the caller stops iterating or ctx is done.
+func decodeStream[T any](ctx context.Context, body io.ReadCloser) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		defer body.Close()
+		dec := json.NewDecoder(body)
+		for {
+			var r T
+			if err := ctx.Err(); err != nil {
+				yield(r, err)
+				return
+			}
+			if err := dec.Decode(&r); err == io.EOF {
+				return
+			} else if err != nil {
+				yield(r, err)
+				return
+			}
+			if !yield(r, nil) {
+				return
+			}
+		}
+	}
+}
+
+// empty is a stream without any values
+func empty[T any](yield func(T, error) bool) {}
+
+// collect drains a stream into a slice, stopping at the first error
+func collect[T any](seq iter.Seq2[T, error]) ([]T, error) {
+	result := []T{}
+	for r, err := range seq {
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, r)
+	}
+	return result, nil
+}
+
+// collectLookup is a helper function that drains the stream returned by a Stream method
+func collectLookup[T any](seq iter.Seq2[T, error], resp *Response, err error) ([]T, *Response, error) {
+	if err != nil {
+		return nil, resp, err
+	}
+	result, err := collect(seq)
+	if err != nil {
+		return nil, resp, err
+	}
+	return result, resp, nil
+}
+
+// streamLookup is a helper function that performs a lookup request and streams the decoded results
+func streamLookup[T any](ctx context.Context, c *Client, path string, opt LookupOptions) (iter.Seq2[T, error], *Response, error) {
+	return streamVersion[T](ctx, c, c.APIVersion, path, opt)
+}
+
+// streamVersion is a helper function like streamLookup that speaks a specific APIVersion regardless of the client
+func streamVersion[T any](ctx context.Context, c *Client, version APIVersion, path string, opt interface{}) (iter.Seq2[T, error], *Response, error) {
+	if version == APIv2 {
+		path = v2Prefix + path
+	}
+	req, err := c.newLookupRequest(ctx, "GET", path, opt)
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header.Set("Accept", version.accept())
+
+	if c.Cache != nil {
+		return streamCached(ctx, c.Cache, req, func() (iter.Seq2[T, error], *Response, error) {
+			return doStream[T](ctx, c, version, req)
+		})
+	}
+	return doStream[T](ctx, c, version, req)
+}
+
+// doStream is a helper function that sends a lookup request and streams the decoded results
+func doStream[T any](ctx context.Context, c *Client, version APIVersion, req *http.Request) (iter.Seq2[T, error], *Response, error) {
+	resp, err := c.Do(req)
+	if err != nil {
+		// APIv1 reports a query without results as an error, which is usually not what is wanted
+		if !c.NoResultsAsError && errors.Is(err, ErrNoResults) {
+			return empty[T], resp, nil
+		}
+		return nil, resp, err
+	}
+
+	if version == APIv2 {
+		return decodeSAF[T](ctx, resp, c.NoResultsAsError), resp, nil
+	}
+	return decodeStream[T](ctx, resp.Body), resp, nil
+}
@@ -0,0 +1,61 @@
+package dnsdb
+
+import (
+	"github.com/stretchr/testify/assert"
+
+	"context"
+	"io"
+	"strings"
+	"testing"
+)
+
+// Make a test body that records when it is closed
+type closeRecorder struct {
+	io.Reader
+	Closed bool
+}
+
+func (c *closeRecorder) Close() error {
+	c.Closed = true
+	return nil
+}
+
+func Test_decodeStream(t *testing.T) {
+	// Verify that every value is yielded and the body is closed at EOF
+	body := &closeRecorder{Reader: strings.NewReader(`{"rrname":"a."}
+{"rrname":"b."}
+{"rrname":"c."}
+`)}
+	var names []string
+	for r, err := range decodeStream[RData](context.Background(), body) {
+		assert.Nil(t, err)
+		names = append(names, *r.RRName)
+	}
+	assert.Equal(t, []string{"a.", "b.", "c."}, names)
+	assert.True(t, body.Closed)
+
+	// Verify that stopping early closes the body
+	body = &closeRecorder{Reader: strings.NewReader(`{"rrname":"a."}
+{"rrname":"b."}
+`)}
+	for range decodeStream[RData](context.Background(), body) {
+		break
+	}
+	assert.True(t, body.Closed)
+
+	// Verify that an invalid value is yielded as an error
+	body = &closeRecorder{Reader: strings.NewReader(`{"rrname":"a."}
+{`)}
+	result, err := collect(decodeStream[RData](context.Background(), body))
+	assert.NotNil(t, err)
+	assert.Nil(t, result)
+	assert.True(t, body.Closed)
+
+	// Verify that a cancelled context stops the stream
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	body = &closeRecorder{Reader: strings.NewReader(`{"rrname":"a."}`)}
+	_, err = collect(decodeStream[RData](ctx, body))
+	assert.Equal(t, context.Canceled, err)
+	assert.True(t, body.Closed)
+}
@@ -0,0 +1,39 @@
+package dnsdb
+
+// Imports
+import (
+	"context"
+	"errors"
+)
+
+// Summary as described at https://docs.dnsdb.info/dnsdb-apiv2/#summarize-results
+type Summary struct {
+	Count         *uint64    `json:"count"`
+	NumResults    *uint64    `json:"num_results"`
+	TimeFirst     *Timestamp `json:"time_first"`
+	TimeLast      *Timestamp `json:"time_last"`
+	ZoneTimeFirst *Timestamp `json:"zone_time_first"`
+	ZoneTimeLast  *Timestamp `json:"zone_time_last"`
+}
+
+// summarize is a helper function that performs a summarize request and decodes the single result.
+// Without any results the summary is nil, or the error is ErrNoResults if the client has NoResultsAsError set.
+func summarize(ctx context.Context, c *Client, path string, opt LookupOptions) (*Summary, *Response, error) {
+	seq, resp, err := streamLookup[Summary](ctx, c, path, opt)
+	if err != nil {
+		return nil, resp, err
+	}
+	result, err := collect(seq)
+	if err != nil {
+		return nil, resp, err
+	}
+	if len(result) == 0 {
+		if c.NoResultsAsError {
+			return nil, resp, ErrNoResults
+		}
+		return nil, resp, nil
+	} else if len(result) != 1 {
+		return nil, resp, errors.New("dnsdb: expected a single summary")
+	}
+	return &result[0], resp, nil
+}
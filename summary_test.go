@@ -0,0 +1,97 @@
+package dnsdb
+
+import (
+	"github.com/stretchr/testify/assert"
+
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func Test_RRSetService_SummarizeName(t *testing.T) {
+	// Setup a client
+	c := NewClient(nil)
+
+	// Verify that an error response fails
+	errorServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "Oh No", 500)
+	}))
+	defer errorServer.Close()
+	u, err := url.Parse(errorServer.URL)
+	assert.Nil(t, err)
+	c.BaseURL = u
+	_, _, err = c.RRSet.SummarizeName(context.Background(), "farsightsecurity.com", nil)
+	assert.NotNil(t, err)
+
+	// Verify that an empty response has no summary, unless NoResultsAsError is set
+	emptyServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer emptyServer.Close()
+	u, err = url.Parse(emptyServer.URL)
+	assert.Nil(t, err)
+	c.BaseURL = u
+	summary, _, err := c.RRSet.SummarizeName(context.Background(), "farsightsecurity.com", nil)
+	assert.Nil(t, err)
+	assert.Nil(t, summary)
+	c.NoResultsAsError = true
+	_, _, err = c.RRSet.SummarizeName(context.Background(), "farsightsecurity.com", nil)
+	assert.True(t, IsNoResults(err))
+	c.NoResultsAsError = false
+
+	// Verify that it gets and parses a response correctly
+	reportServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/summarize/rrset/name/farsightsecurity.com/NS", r.URL.Path)
+		assert.Equal(t, "1000", r.URL.Query().Get("max_count"))
+		io.WriteString(w, `{"count":495292,"num_results":2,"time_first":1372688083,"time_last":1468324876,"zone_time_first":1374250920,"zone_time_last":1468253883}`)
+	}))
+	defer reportServer.Close()
+	u, err = url.Parse(reportServer.URL)
+	assert.Nil(t, err)
+	c.BaseURL = u
+	actual, _, err := c.RRSet.SummarizeName(context.Background(), "farsightsecurity.com", &RRSetLookupNameOptions{
+		RRType: "NS",
+		LookupOptions: LookupOptions{
+			MaxCount: 1000,
+		},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, &Summary{
+		Count:         Uint64(495292),
+		NumResults:    Uint64(2),
+		TimeFirst:     NewTimestamp(1372688083),
+		TimeLast:      NewTimestamp(1468324876),
+		ZoneTimeFirst: NewTimestamp(1374250920),
+		ZoneTimeLast:  NewTimestamp(1468253883),
+	}, actual)
+}
+
+func Test_RDataService_SummarizeIP(t *testing.T) {
+	// Setup a client speaking APIv2
+	c := NewClient(nil)
+	c.APIVersion = APIv2
+
+	// Verify that it gets and parses a SAF response correctly
+	reportServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/dnsdb/v2/summarize/rdata/ip/104.244.13.104", r.URL.Path)
+		io.WriteString(w, `{"cond":"begin"}
+{"obj":{"count":9453,"num_results":2,"time_first":1427897872,"time_last":1468333042}}
+{"cond":"succeeded"}
+`)
+	}))
+	defer reportServer.Close()
+	u, err := url.Parse(reportServer.URL)
+	assert.Nil(t, err)
+	c.BaseURL = u
+	actual, resp, err := c.RData.SummarizeIP(context.Background(), net.ParseIP("104.244.13.104"), nil)
+	assert.Nil(t, err)
+	assert.Equal(t, ConditionSucceeded, resp.Condition)
+	assert.Equal(t, &Summary{
+		Count:      Uint64(9453),
+		NumResults: Uint64(2),
+		TimeFirst:  NewTimestamp(1427897872),
+		TimeLast:   NewTimestamp(1468333042),
+	}, actual)
+}
@@ -2,6 +2,7 @@ package dnsdb
 
 // Imports
 import (
+	"encoding/json"
 	"strconv"
 	"time"
 )
@@ -18,9 +19,29 @@ func NewTimestamp(secs int64) *Timestamp {
 	}
 }
 
-// UnmarshalJSON helps unmarshal UNIX dates in JSON
+// humanTimeLayouts are the formats of times returned when the humantime option is set
+var humanTimeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+}
+
+// UnmarshalJSON helps unmarshal UNIX dates in JSON, as well as the strings returned when the humantime option is set
 func (t *Timestamp) UnmarshalJSON(data []byte) error {
 	var err error
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err = json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		for _, layout := range humanTimeLayouts {
+			var parsed time.Time
+			if parsed, err = time.ParseInLocation(layout, s, time.UTC); err == nil {
+				(*t).Time = parsed.Local()
+				return nil
+			}
+		}
+		data = []byte(s)
+	}
 	i, err := strconv.ParseInt(string(data), 10, 64)
 	if err != nil {
 		return err
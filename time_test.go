@@ -0,0 +1,31 @@
+package dnsdb
+
+import (
+	"github.com/stretchr/testify/assert"
+
+	"testing"
+)
+
+func Test_Timestamp_UnmarshalJSON(t *testing.T) {
+	// Verify that UNIX timestamps are parsed
+	var ts Timestamp
+	assert.Nil(t, ts.UnmarshalJSON([]byte(`1372688083`)))
+	assert.Equal(t, *NewTimestamp(1372688083), ts)
+
+	// Verify that humantime strings are parsed in either format
+	ts = Timestamp{}
+	assert.Nil(t, ts.UnmarshalJSON([]byte(`"2013-07-01 14:14:43"`)))
+	assert.Equal(t, *NewTimestamp(1372688083), ts)
+	ts = Timestamp{}
+	assert.Nil(t, ts.UnmarshalJSON([]byte(`"2013-07-01T14:14:43Z"`)))
+	assert.Equal(t, *NewTimestamp(1372688083), ts)
+
+	// Verify that quoted UNIX timestamps are parsed
+	ts = Timestamp{}
+	assert.Nil(t, ts.UnmarshalJSON([]byte(`"1372688083"`)))
+	assert.Equal(t, *NewTimestamp(1372688083), ts)
+
+	// Verify that garbage fails
+	assert.NotNil(t, ts.UnmarshalJSON([]byte(`"yesterday"`)))
+	assert.NotNil(t, ts.UnmarshalJSON([]byte(`true`)))
+}
@@ -0,0 +1,60 @@
+package dnsdb
+
+// Imports
+import (
+	"strings"
+)
+
+// LeftWildcard returns the owner name "*.domain" which matches every name below domain, for subdomain discovery
+func LeftWildcard(domain string) (string, error) {
+	domain = strings.TrimSuffix(domain, ".")
+	if domain == "" || strings.Contains(domain, "*") {
+		return "", &ValidationError{Field: "domain", Value: domain, Reason: "must be a non-empty name without wildcards"}
+	}
+	return normalizeName("name", "*."+domain, true)
+}
+
+// RightWildcard returns the owner name "name.*" which matches name under any TLD, for TLD sweeps
+func RightWildcard(name string) (string, error) {
+	name = strings.TrimSuffix(name, ".")
+	if name == "" || strings.Contains(name, "*") {
+		return "", &ValidationError{Field: "name", Value: name, Reason: "must be a non-empty name without wildcards"}
+	}
+	return normalizeName("name", name+".*", true)
+}
+
+// WildcardMatch reports if name matches a left or right wildcard and returns the lowercase part of name matched by
+// the "*": the subdomain for a left wildcard ("www" for "www.example.com." and "*.example.com") or the TLD for a
+// right wildcard ("co.uk" for "www.example.co.uk." and "www.example.*").
+func WildcardMatch(wildcard, name string) (string, bool) {
+	w := strings.ToLower(strings.TrimSuffix(wildcard, "."))
+	n := strings.ToLower(strings.TrimSuffix(name, "."))
+	switch {
+	case strings.HasPrefix(w, "*."):
+		suffix := w[1:]
+		if len(n) > len(suffix) && strings.HasSuffix(n, suffix) {
+			return n[:len(n)-len(suffix)], true
+		}
+	case strings.HasSuffix(w, ".*"):
+		prefix := w[:len(w)-1]
+		if len(n) > len(prefix) && strings.HasPrefix(n, prefix) {
+			return n[len(prefix):], true
+		}
+	}
+	return "", false
+}
+
+// GroupByWildcard groups the results of a wildcard lookup by the part of their owner name matched by the wildcard,
+// see WildcardMatch. Results which do not match, including those without an rrname, are omitted.
+func GroupByWildcard(records []RRSet, wildcard string) map[string][]RRSet {
+	groups := make(map[string][]RRSet)
+	for _, r := range records {
+		if r.RRName == nil {
+			continue
+		}
+		if label, ok := WildcardMatch(wildcard, *r.RRName); ok {
+			groups[label] = append(groups[label], r)
+		}
+	}
+	return groups
+}
@@ -0,0 +1,85 @@
+package dnsdb
+
+import (
+	"github.com/stretchr/testify/assert"
+
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func Test_LeftWildcard(t *testing.T) {
+	// Verify that a left wildcard is built for a domain
+	name, err := LeftWildcard("example.com.")
+	assert.Nil(t, err)
+	assert.Equal(t, "*.example.com", name)
+
+	// Verify that invalid domains fail
+	var verr *ValidationError
+	for _, domain := range []string{"", ".", "*.example.com", "example..com"} {
+		_, err := LeftWildcard(domain)
+		assert.True(t, errors.As(err, &verr), domain)
+	}
+}
+
+func Test_RightWildcard(t *testing.T) {
+	// Verify that a right wildcard is built for a name
+	name, err := RightWildcard("www.example")
+	assert.Nil(t, err)
+	assert.Equal(t, "www.example.*", name)
+
+	// Verify that invalid names fail
+	var verr *ValidationError
+	for _, n := range []string{"", "www.*", "www..example"} {
+		_, err := RightWildcard(n)
+		assert.True(t, errors.As(err, &verr), n)
+	}
+}
+
+func Test_WildcardMatch(t *testing.T) {
+	for _, tc := range []struct {
+		wildcard, name, label string
+		ok                    bool
+	}{
+		{"*.example.com", "www.example.com.", "www", true},
+		{"*.example.com.", "a.b.Example.COM.", "a.b", true},
+		{"*.example.com", "example.com.", "", false},
+		{"*.example.com", "badexample.com.", "", false},
+		{"www.example.*", "www.example.co.uk.", "co.uk", true},
+		{"www.example.*", "www.example.", "", false},
+		{"www.example.*", "www.examples.com.", "", false},
+		{"example.com", "example.com.", "", false},
+	} {
+		label, ok := WildcardMatch(tc.wildcard, tc.name)
+		assert.Equal(t, tc.ok, ok, tc.name)
+		assert.Equal(t, tc.label, label, tc.name)
+	}
+}
+
+func Test_GroupByWildcard(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/lookup/rrset/name/www.example.*", r.URL.EscapedPath())
+		io.WriteString(w, `{"rrname":"www.example.com.","rrtype":"A","rdata":["192.0.2.1"]}
+{"rrname":"www.example.com.","rrtype":"AAAA","rdata":["2001:db8::1"]}
+{"rrname":"www.example.co.uk.","rrtype":"A","rdata":["192.0.2.2"]}`)
+	}))
+	defer server.Close()
+	c := NewClient(nil)
+	u, err := url.Parse(server.URL + "/")
+	assert.Nil(t, err)
+	c.BaseURL = u
+
+	// Verify that a TLD sweep groups the results by TLD
+	name, err := RightWildcard("www.example")
+	assert.Nil(t, err)
+	records, _, err := c.RRSet.LookupName(context.Background(), name, nil)
+	assert.Nil(t, err)
+	groups := GroupByWildcard(append(records, RRSet{}), name)
+	assert.Len(t, groups, 2)
+	assert.Len(t, groups["com"], 2)
+	assert.Len(t, groups["co.uk"], 1)
+}